@@ -0,0 +1,62 @@
+package cli_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"jwt/internal/config"
+	"jwt/internal/domain/hash"
+	"jwt/internal/interface/cli"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func TestDiffCommand(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := "diff-test-secret-with-32-bytes!!!!"
+	encoder := jwtusecase.NewEncoder(hasher, []byte(secret))
+	iat := time.Now().Add(-2 * time.Hour).Unix()
+	tokenA, _ := encoder.Encode(map[string]any{"sub": "alice", "iat": iat, "exp": iat + 7200 + 3600, "roles": []string{"user", "admin"}})
+	tokenB, _ := encoder.Encode(map[string]any{"sub": "alice", "iat": iat, "exp": iat + 3600, "roles": []string{"user"}})
+
+	settings := config.Settings{Algorithm: "HS256", SecretKey: secret}
+	decoder, err := cli.NewDecoder(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := cli.NewHandler(decoder, cli.WithSettings(settings))
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	runErr := handler.Run("-validate", "diff", tokenA, tokenB)
+	w.Close()
+	os.Stdout = old
+	data, _ := io.ReadAll(r)
+	output := string(data)
+
+	if runErr != nil {
+		t.Fatalf("Unexpected error: %v", runErr)
+	}
+	for _, want := range []string{
+		"Header:\n  (no differences)",
+		"~ exp: ",
+		"[-2h0m0s]",
+		"- roles[1]: \"admin\"",
+		"A: valid",
+		"B: token is expired",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	if err := handler.Run("diff", tokenA); err == nil {
+		t.Error("Expected error with a single token")
+	}
+}
@@ -0,0 +1,60 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/key"
+	"jwt/internal/interface/cli"
+)
+
+func TestKeysGenerate_OnlyHasherAlgorithms(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantAlg string
+	}{
+		{"RSA", []string{"-type", "rsa"}, "RS256"},
+		{"RSA with PS256", []string{"-type", "rsa", "-alg", "PS256"}, "PS256"},
+		{"EC P-256", []string{"-type", "ec"}, "ES256"},
+		{"HMAC secret", []string{"-type", "oct", "-bits", "384"}, "HS384"},
+		{"EC P-384", []string{"-type", "ec", "-bits", "384"}, ""},
+		{"EC P-521", []string{"-type", "ec", "-bits", "521"}, ""},
+		{"Ed25519", []string{"-type", "ed25519"}, ""},
+		{"Algorithm for another key type", []string{"-type", "rsa", "-alg", "ES256"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "key")
+			args := append([]string{"keys", "generate", "-format", "jwk", "-out", out}, tt.args...)
+			err := cli.NewHandler(nil).Run(args...)
+			if tt.wantAlg == "" {
+				if err == nil {
+					t.Error("Expected the key to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(out + ".jwk")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var jwk key.JWK
+			if err := json.Unmarshal(data, &jwk); err != nil {
+				t.Fatal(err)
+			}
+			if jwk.Alg != tt.wantAlg {
+				t.Errorf("Expected alg %s, got %s", tt.wantAlg, jwk.Alg)
+			}
+			if _, err := hash.NewHasher(hash.Algorithm(jwk.Alg)); err != nil {
+				t.Errorf("Expected a hasher for %s, got %v", jwk.Alg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cli_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"jwt/internal/config"
+	"jwt/internal/interface/cli"
+	"jwt/internal/interface/revocation"
+)
+
+func TestRevokeCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.json")
+	handler := cli.NewHandler(nil, cli.WithSettings(config.Settings{Denylist: path}))
+
+	steps := [][]string{
+		{"revoke", "add", "-jti", "abc"},
+		{"revoke", "add", "-sub", "user-1", "-before", "2024-05-01T00:00:00Z"},
+		{"revoke", "add", "-kid", "k1"},
+		{"revoke", "remove", "-kid", "k1"},
+		{"revoke", "list"},
+	}
+	for _, args := range steps {
+		if err := handler.Run(args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	list, err := revocation.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.JTIs) != 1 || list.Subjects["user-1"] != 1714521600 || len(list.KeyIDs) != 0 {
+		t.Errorf("Unexpected denylist: %+v", list)
+	}
+
+	if err := handler.Run("revoke", "add", "-jti", "x", "-kid", "y"); err == nil {
+		t.Error("Expected error when more than one entry is given")
+	}
+	if err := cli.NewHandler(nil).Run("revoke", "list"); err == nil {
+		t.Error("Expected error without a denylist file")
+	}
+}
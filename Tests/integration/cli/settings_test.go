@@ -0,0 +1,36 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"jwt/internal/config"
+	"jwt/internal/interface/cli"
+)
+
+func TestNewDecoder_RejectsHMACWithPublicKeySources(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    config.Settings
+		errContains string
+	}{
+		{"JWKS URL", config.Settings{Algorithm: "HS256", JWKSURL: "https://idp.example.com/jwks.json"}, "-jwks-url cannot be used with HS256"},
+		{"Trusted key URLs", config.Settings{Algorithm: "HS384", KeyURLs: "https://idp.example.com/keys"}, "-trusted-key-urls cannot be used with HS384"},
+		{"x5c roots", config.Settings{Algorithm: "HS512", X5CRoots: "roots.pem"}, "-x5c-roots cannot be used with HS512"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cli.NewDecoder(tt.settings); err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+			if _, err := cli.NewVerifier(tt.settings); err == nil {
+				t.Error("Expected NewVerifier to reject the settings too")
+			}
+		})
+	}
+
+	settings := config.Settings{Algorithm: "RS256", JWKSURL: "https://idp.example.com/jwks.json"}
+	if _, err := cli.NewDecoder(settings); err != nil {
+		t.Errorf("Expected RS256 with a JWKS URL to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package cli_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"jwt/internal/config"
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/key"
+	"jwt/internal/interface/cli"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+// runSign runs the sign command and returns the printed token
+func runSign(t *testing.T, settings config.Settings, args ...string) (string, error) {
+	t.Helper()
+	handler := cli.NewHandler(nil, cli.WithSettings(settings))
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	runErr := handler.Run(append([]string{"sign"}, args...)...)
+	w.Close()
+	os.Stdout = old
+	data, _ := io.ReadAll(r)
+	return strings.TrimSpace(string(data)), runErr
+}
+
+func TestSignCommand(t *testing.T) {
+	t.Run("HMAC secret", func(t *testing.T) {
+		secret := "sign-test-secret-with-32-bytes!!!"
+		token, err := runSign(t, config.Settings{Algorithm: "HS256", SecretKey: secret}, "-kid", "k1", `{"sub":"alice"}`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		hasher, _ := hash.NewHasher(hash.HS256)
+		verified, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey([]byte(secret))).Verify(token)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		if verified.Claims["sub"] != "alice" || verified.Header["kid"] != "k1" {
+			t.Errorf("Unexpected token: header %v, claims %v", verified.Header, verified.Claims)
+		}
+	})
+
+	t.Run("ES256 key file", func(t *testing.T) {
+		privateKey, err := key.Generate(key.EC, 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pemData, err := key.MarshalPrivateKeyPEM(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyFile := filepath.Join(t.TempDir(), "ec.pem")
+		if err := os.WriteFile(keyFile, pemData, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		token, err := runSign(t, config.Settings{Algorithm: "ES256"}, "-key", keyFile, `{"sub":"alice"}`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		public, err := key.Public(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hasher, _ := hash.NewHasher(hash.ES256)
+		if _, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithVerificationKey(public)).Verify(token); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			settings    config.Settings
+			args        []string
+			errContains string
+		}{
+			{"No claims", config.Settings{Algorithm: "HS256", SecretKey: "x"}, nil, "claims object is required"},
+			{"Claims not an object", config.Settings{Algorithm: "HS256", SecretKey: "x"}, []string{`[1]`}, "claims must be a JSON object"},
+			{"No key", config.Settings{Algorithm: "RS256"}, []string{`{}`}, "signing key is required"},
+			{"PKCS#11 with HMAC", config.Settings{Algorithm: "HS256"}, []string{"-pkcs11-module", "/nonexistent.so", `{}`}, "requires an RS, PS or ES algorithm"},
+			{"PKCS#11 with key file", config.Settings{Algorithm: "RS256"}, []string{"-pkcs11-module", "/nonexistent.so", "-key", "k.pem", `{}`}, "cannot be combined"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := runSign(t, tt.settings, tt.args...)
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+			})
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package hash_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"jwt/internal/domain/hash"
@@ -27,32 +28,43 @@ func testHasher(t *testing.T, h hash.Hasher, expectedName string) {
 
 	// Test data
 	data := []byte("test data")
-	key := []byte("test key")
+	key := []byte(strings.Repeat("test key ", 8))
 
 	// Test signing
-	signature := h.Sign(data, key)
-	if signature == "" {
+	signature, err := h.Sign(data, key)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if len(signature) == 0 {
 		t.Error("Sign returned empty signature")
 	}
 
 	// Test verification
-	if !h.Verify(data, signature, key) {
-		t.Error("Verify failed for valid signature")
+	if err := h.Verify(data, signature, key); err != nil {
+		t.Errorf("Verify failed for valid signature: %v", err)
 	}
 
 	// Test verification with wrong key
-	if h.Verify(data, signature, []byte("wrong key")) {
-		t.Error("Verify succeeded with wrong key")
+	if err := h.Verify(data, signature, []byte("wrong key")); !errors.Is(err, hash.ErrSignatureInvalid) {
+		t.Errorf("Verify with wrong key: expected ErrSignatureInvalid, got %v", err)
 	}
 
 	// Test verification with wrong data
-	if h.Verify([]byte("wrong data"), signature, key) {
-		t.Error("Verify succeeded with wrong data")
+	if err := h.Verify([]byte("wrong data"), signature, key); !errors.Is(err, hash.ErrSignatureInvalid) {
+		t.Errorf("Verify with wrong data: expected ErrSignatureInvalid, got %v", err)
 	}
 
 	// Test empty key
-	if h.Sign(data, []byte{}) != "" {
-		t.Error("Sign should return empty string for empty key")
+	if _, err := h.Sign(data, []byte{}); !errors.Is(err, hash.ErrInvalidKey) {
+		t.Errorf("Sign with empty key: expected ErrInvalidKey, got %v", err)
+	}
+	if err := h.Verify(data, signature, []byte{}); !errors.Is(err, hash.ErrInvalidKey) {
+		t.Errorf("Verify with empty key: expected ErrInvalidKey, got %v", err)
+	}
+
+	// Test key shorter than the hash size (RFC 7518 section 3.2)
+	if _, err := h.Sign(data, []byte("test key")); !errors.Is(err, hash.ErrInvalidKey) {
+		t.Errorf("Sign with short key: expected ErrInvalidKey, got %v", err)
 	}
 }
 
@@ -78,6 +90,16 @@ func TestNewHasher(t *testing.T) {
 			algorithm: hash.HS512,
 			wantErr:   false,
 		},
+		{
+			name:      "PS256",
+			algorithm: hash.PS256,
+			wantErr:   false,
+		},
+		{
+			name:      "ES256",
+			algorithm: hash.ES256,
+			wantErr:   false,
+		},
 		{
 			name:        "Invalid algorithm",
 			algorithm:   "INVALID",
@@ -96,6 +118,9 @@ func TestNewHasher(t *testing.T) {
 				if tt.errContains != "" && !strings.Contains(err.Error(), tt.errContains) {
 					t.Errorf("Expected error to contain %q, got %v", tt.errContains, err)
 				}
+				if !errors.Is(err, hash.ErrUnsupportedAlgorithm) {
+					t.Errorf("Expected ErrUnsupportedAlgorithm, got %v", err)
+				}
 				return
 			}
 
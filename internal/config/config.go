@@ -0,0 +1,423 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables consulted when resolving settings
+const (
+	// EnvConfig overrides the default config file location
+	EnvConfig = "JWT_CONFIG"
+	// EnvSecretKey holds the HMAC secret used for validation
+	EnvSecretKey = "JWT_SECRET_KEY"
+	// EnvPublicKey holds the PEM encoded public key used for validation
+	EnvPublicKey = "JWT_PUBLIC_KEY"
+)
+
+// ErrProfileNotFound is returned when a requested profile does not exist
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Profile holds the settings for a single named environment
+type Profile struct {
+	Algorithm     string
+	SecretKey     string
+	SecretKeyFile string
+	PublicKey     string
+	PublicKeyFile string
+	JWKSURL       string
+	Issuer        string
+	Audience      string
+	Leeway        time.Duration
+	Denylist      string
+	KeyringFile   string
+	X5CRoots      string
+	KeyURLs       string
+	Strict        bool
+	MaxTokenSize  int
+}
+
+// Config is the parsed contents of a config file
+type Config struct {
+	// DefaultProfile is used when no profile is requested explicitly
+	DefaultProfile string
+	// Profiles maps profile names to their settings
+	Profiles map[string]Profile
+}
+
+// Settings is the effective configuration after merging all sources
+type Settings struct {
+	Algorithm string
+	SecretKey string
+	PublicKey string
+	JWKSURL   string
+	Issuer    string
+	Audience  string
+	Leeway    time.Duration
+	Denylist  string
+	Keyring   string
+	X5CRoots  string
+	// KeyURLs is a comma-separated list of trusted jku/x5u URL prefixes
+	KeyURLs string
+	// Strict enables strict parsing; MaxTokenSize is its size limit in bytes
+	Strict       bool
+	MaxTokenSize int
+}
+
+// DefaultPath returns the config file location, honouring JWT_CONFIG
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "jwt", "config.toml"), nil
+}
+
+// Load reads and parses the config file at path
+func Load(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	cfg, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Key files are resolved relative to the config file
+	base := filepath.Dir(path)
+	for name, p := range cfg.Profiles {
+		if p.SecretKeyFile != "" && p.SecretKey == "" {
+			data, err := os.ReadFile(resolvePath(base, p.SecretKeyFile))
+			if err != nil {
+				return nil, fmt.Errorf("profile %q: failed to read secret key file: %w", name, err)
+			}
+			p.SecretKey = strings.TrimRight(string(data), "\r\n")
+		}
+		if p.PublicKeyFile != "" && p.PublicKey == "" {
+			data, err := os.ReadFile(resolvePath(base, p.PublicKeyFile))
+			if err != nil {
+				return nil, fmt.Errorf("profile %q: failed to read public key file: %w", name, err)
+			}
+			p.PublicKey = string(data)
+		}
+		if p.Denylist != "" {
+			p.Denylist = resolvePath(base, p.Denylist)
+		}
+		if p.KeyringFile != "" {
+			p.KeyringFile = resolvePath(base, p.KeyringFile)
+		}
+		if p.X5CRoots != "" {
+			p.X5CRoots = resolvePath(base, p.X5CRoots)
+		}
+		cfg.Profiles[name] = p
+	}
+
+	return cfg, nil
+}
+
+// Parse reads a config file in a small subset of TOML:
+//
+//	default_profile = "staging"
+//
+//	[profiles.staging]
+//	algorithm = "RS256"
+//	public_key_file = "keys/staging.pem"
+//	jwks_url = "https://auth.staging.example.com/jwks.json"
+//	issuer = "https://auth.staging.example.com"
+//	audience = "api"
+//	leeway = "30s"
+//	denylist = "revoked.json"
+//	keyring_file = "keyring.json"
+//	x5c_roots = "partner-roots.pem"
+//	trusted_key_urls = "https://idp.example.com/keys/,https://partner.example.com/jwks"
+//	strict = true
+//	max_token_size = 8192
+func Parse(r io.Reader) (*Config, error) {
+	cfg := &Config{Profiles: make(map[string]Profile)}
+	current := ""
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		// Table header
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			table := strings.TrimSpace(line[1 : len(line)-1])
+			name, ok := strings.CutPrefix(table, "profiles.")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("line %d: unsupported table %q", lineNo, table)
+			}
+			name, err := unquote(name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid profile name: %w", lineNo, err)
+			}
+			if _, exists := cfg.Profiles[name]; exists {
+				return nil, fmt.Errorf("line %d: duplicate profile %q", lineNo, name)
+			}
+			cfg.Profiles[name] = Profile{}
+			current = name
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value, err := unquote(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		if current == "" {
+			if key != "default_profile" {
+				return nil, fmt.Errorf("line %d: unknown top-level key %q", lineNo, key)
+			}
+			cfg.DefaultProfile = value
+			continue
+		}
+
+		profile := cfg.Profiles[current]
+		if err := profile.set(key, value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		cfg.Profiles[current] = profile
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Profile returns the named profile, or the default profile when name is empty
+func (c *Config) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return profile, nil
+}
+
+// Resolve merges the sources in order of precedence, highest first:
+//
+//  1. command line flags (non-zero fields of flags)
+//  2. environment variables (JWT_SECRET_KEY, JWT_PUBLIC_KEY)
+//  3. the selected profile
+//  4. built-in defaults (HS256)
+func Resolve(profile Profile, flags Settings, getenv func(string) string) Settings {
+	settings := Settings{
+		Algorithm:    "HS256",
+		SecretKey:    profile.SecretKey,
+		PublicKey:    profile.PublicKey,
+		JWKSURL:      profile.JWKSURL,
+		Issuer:       profile.Issuer,
+		Audience:     profile.Audience,
+		Leeway:       profile.Leeway,
+		Denylist:     profile.Denylist,
+		Keyring:      profile.KeyringFile,
+		X5CRoots:     profile.X5CRoots,
+		KeyURLs:      profile.KeyURLs,
+		Strict:       profile.Strict,
+		MaxTokenSize: profile.MaxTokenSize,
+	}
+	if profile.Algorithm != "" {
+		settings.Algorithm = profile.Algorithm
+	}
+
+	if v := getenv(EnvSecretKey); v != "" {
+		settings.SecretKey = v
+	}
+	if v := getenv(EnvPublicKey); v != "" {
+		settings.PublicKey = v
+	}
+
+	if flags.Algorithm != "" {
+		settings.Algorithm = flags.Algorithm
+	}
+	if flags.SecretKey != "" {
+		settings.SecretKey = flags.SecretKey
+	}
+	if flags.PublicKey != "" {
+		settings.PublicKey = flags.PublicKey
+	}
+	if flags.JWKSURL != "" {
+		settings.JWKSURL = flags.JWKSURL
+	}
+	if flags.Issuer != "" {
+		settings.Issuer = flags.Issuer
+	}
+	if flags.Audience != "" {
+		settings.Audience = flags.Audience
+	}
+	if flags.Leeway != 0 {
+		settings.Leeway = flags.Leeway
+	}
+	if flags.Denylist != "" {
+		settings.Denylist = flags.Denylist
+	}
+	if flags.Keyring != "" {
+		settings.Keyring = flags.Keyring
+	}
+	if flags.X5CRoots != "" {
+		settings.X5CRoots = flags.X5CRoots
+	}
+	if flags.KeyURLs != "" {
+		settings.KeyURLs = flags.KeyURLs
+	}
+	if flags.Strict {
+		settings.Strict = true
+	}
+	if flags.MaxTokenSize != 0 {
+		settings.MaxTokenSize = flags.MaxTokenSize
+	}
+
+	settings.Algorithm = strings.ToUpper(settings.Algorithm)
+	return settings
+}
+
+// VerificationKey returns the key material matching the configured algorithm
+func (s Settings) VerificationKey() []byte {
+	if strings.HasPrefix(s.Algorithm, "HS") {
+		return []byte(s.SecretKey)
+	}
+	return []byte(s.PublicKey)
+}
+
+// set assigns a single profile field from its config file key
+func (p *Profile) set(key, value string) error {
+	switch key {
+	case "algorithm":
+		p.Algorithm = value
+	case "secret_key":
+		p.SecretKey = value
+	case "secret_key_file":
+		p.SecretKeyFile = value
+	case "public_key":
+		p.PublicKey = value
+	case "public_key_file":
+		p.PublicKeyFile = value
+	case "jwks_url":
+		p.JWKSURL = value
+	case "issuer":
+		p.Issuer = value
+	case "audience":
+		p.Audience = value
+	case "leeway":
+		leeway, err := ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid leeway: %w", err)
+		}
+		p.Leeway = leeway
+	case "denylist":
+		p.Denylist = value
+	case "keyring_file":
+		p.KeyringFile = value
+	case "x5c_roots":
+		p.X5CRoots = value
+	case "trusted_key_urls":
+		p.KeyURLs = value
+	case "strict":
+		strict, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid strict: expected true or false")
+		}
+		p.Strict = strict
+	case "max_token_size":
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return fmt.Errorf("invalid max_token_size: expected a positive number of bytes")
+		}
+		p.MaxTokenSize = size
+	default:
+		return fmt.Errorf("unknown profile key %q", key)
+	}
+	return nil
+}
+
+// ParseDuration accepts either a Go duration ("30s") or a number of seconds
+func ParseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// unquote removes TOML basic ("...") or literal ('...') string quoting
+func unquote(value string) (string, error) {
+	switch {
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", value)
+		}
+		return s, nil
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1], nil
+	case strings.ContainsAny(value, "\"'"):
+		return "", fmt.Errorf("unterminated string %s", value)
+	default:
+		return value, nil
+	}
+}
+
+// stripComment removes a trailing # comment that is not inside a string
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// resolvePath expands ~ and makes relative paths relative to base
+func resolvePath(base, path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"jwt/internal/config"
+)
+
+const testConfig = `
+# Shared team config
+default_profile = "staging"
+
+[profiles.staging]
+algorithm = "rs256"
+public_key_file = "staging.pem"
+issuer = "https://auth.staging.example.com" # inline comment
+audience = 'api'
+leeway = "30s"
+denylist = "revoked.json"
+keyring_file = "keyring.json"
+x5c_roots = "roots.pem"
+trusted_key_urls = "https://auth.staging.example.com/keys/"
+strict = true
+max_token_size = 8192
+
+[profiles.local]
+secret_key = "local#secret"
+leeway = 5
+`
+
+func TestParse(t *testing.T) {
+	cfg, err := config.Parse(strings.NewReader(testConfig))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if cfg.DefaultProfile != "staging" {
+		t.Errorf("Expected default profile staging, got %q", cfg.DefaultProfile)
+	}
+
+	staging := cfg.Profiles["staging"]
+	if staging.Algorithm != "rs256" || staging.Issuer != "https://auth.staging.example.com" || staging.Audience != "api" {
+		t.Errorf("Unexpected staging profile: %+v", staging)
+	}
+	if staging.Leeway != 30*time.Second {
+		t.Errorf("Expected leeway 30s, got %v", staging.Leeway)
+	}
+	if staging.KeyURLs != "https://auth.staging.example.com/keys/" {
+		t.Errorf("Expected trusted key URLs, got %q", staging.KeyURLs)
+	}
+	if !staging.Strict || staging.MaxTokenSize != 8192 {
+		t.Errorf("Expected strict parsing with an 8192 byte limit, got %v, %d", staging.Strict, staging.MaxTokenSize)
+	}
+
+	local := cfg.Profiles["local"]
+	if local.SecretKey != "local#secret" {
+		t.Errorf("Expected secret containing #, got %q", local.SecretKey)
+	}
+	if local.Leeway != 5*time.Second {
+		t.Errorf("Expected leeway 5s, got %v", local.Leeway)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		errContains string
+	}{
+		{"Unknown table", "[servers.a]\n", "unsupported table"},
+		{"Unknown key", "[profiles.a]\ncolor = \"blue\"\n", "unknown profile key"},
+		{"Duplicate profile", "[profiles.a]\n[profiles.a]\n", "duplicate profile"},
+		{"Missing equals", "[profiles.a]\nalgorithm\n", "expected key = value"},
+		{"Bad leeway", "[profiles.a]\nleeway = \"soon\"\n", "invalid leeway"},
+		{"Bad strict", "[profiles.a]\nstrict = \"maybe\"\n", "invalid strict"},
+		{"Bad max token size", "[profiles.a]\nmax_token_size = -1\n", "invalid max_token_size"},
+		{"Unknown top-level key", "algorithm = \"HS256\"\n", "unknown top-level key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.Parse(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error to contain %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestLoad_ReadsKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	pem := "-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n"
+	if err := os.WriteFile(filepath.Join(dir, "staging.pem"), []byte(pem), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	profile, err := cfg.Profile("")
+	if err != nil {
+		t.Fatalf("Profile returned error: %v", err)
+	}
+	if profile.PublicKey != pem {
+		t.Errorf("Expected public key to be read from file, got %q", profile.PublicKey)
+	}
+	if profile.Denylist != filepath.Join(dir, "revoked.json") {
+		t.Errorf("Expected denylist relative to the config file, got %q", profile.Denylist)
+	}
+	if profile.KeyringFile != filepath.Join(dir, "keyring.json") {
+		t.Errorf("Expected keyring file relative to the config file, got %q", profile.KeyringFile)
+	}
+	if profile.X5CRoots != filepath.Join(dir, "roots.pem") {
+		t.Errorf("Expected x5c roots relative to the config file, got %q", profile.X5CRoots)
+	}
+
+	if _, err := cfg.Profile("production"); !errors.Is(err, config.ErrProfileNotFound) {
+		t.Errorf("Expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	profile := config.Profile{
+		Algorithm: "HS384",
+		SecretKey: "profile-secret",
+		Issuer:    "profile-issuer",
+		Audience:  "profile-audience",
+	}
+	env := map[string]string{config.EnvSecretKey: "env-secret"}
+	flags := config.Settings{Issuer: "flag-issuer"}
+
+	settings := config.Resolve(profile, flags, func(k string) string { return env[k] })
+
+	if settings.Algorithm != "HS384" {
+		t.Errorf("Expected algorithm from profile, got %q", settings.Algorithm)
+	}
+	if settings.SecretKey != "env-secret" {
+		t.Errorf("Expected secret from environment, got %q", settings.SecretKey)
+	}
+	if settings.Issuer != "flag-issuer" {
+		t.Errorf("Expected issuer from flags, got %q", settings.Issuer)
+	}
+	if settings.Audience != "profile-audience" {
+		t.Errorf("Expected audience from profile, got %q", settings.Audience)
+	}
+	if string(settings.VerificationKey()) != "env-secret" {
+		t.Errorf("Expected HMAC verification key to be the secret, got %q", settings.VerificationKey())
+	}
+
+	defaults := config.Resolve(config.Profile{}, config.Settings{}, func(string) string { return "" })
+	if defaults.Algorithm != "HS256" {
+		t.Errorf("Expected default algorithm HS256, got %q", defaults.Algorithm)
+	}
+}
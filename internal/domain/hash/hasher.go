@@ -1,6 +1,7 @@
 package hash
 
 import (
+	"crypto"
 	"fmt"
 	"jwt/internal/interface/hash"
 )
@@ -18,18 +19,56 @@ const (
 	HS512 Algorithm = "HS512"
 	// RS256 represents RSA-SHA256 algorithm
 	RS256 Algorithm = "RS256"
+	// PS256 represents RSASSA-PSS with SHA-256
+	PS256 Algorithm = "PS256"
+	// ES256 represents ECDSA with P-256 and SHA-256
+	ES256 Algorithm = "ES256"
 )
 
-// Hasher defines the interface for JWT signature algorithms
+// Errors reported by hashers; match them with errors.Is
+var (
+	// ErrInvalidKey means the key cannot be used with the algorithm
+	ErrInvalidKey = hash.ErrInvalidKey
+	// ErrSignatureInvalid means the signature does not match the data
+	ErrSignatureInvalid = hash.ErrSignatureInvalid
+	// ErrUnsupportedAlgorithm means no hasher implements the algorithm
+	ErrUnsupportedAlgorithm = hash.ErrUnsupportedAlgorithm
+)
+
+// Hasher defines the interface for JWT signature algorithms. Signatures are
+// raw bytes; base64url encoding is left to the token encoder and decoder.
 type Hasher interface {
-	// Sign creates a signature for the given data using the provided key
-	Sign(data []byte, key []byte) string
-	// Verify checks if the signature is valid for the given data and key
-	Verify(data []byte, signature string, key []byte) bool
+	// Sign creates a signature for the given data using the provided key. An
+	// unusable key is reported as ErrInvalidKey.
+	Sign(data, key []byte) ([]byte, error)
+	// Verify returns nil if the signature is valid for the given data and
+	// key, ErrSignatureInvalid if it is not, and ErrInvalidKey if the key
+	// cannot be used
+	Verify(data, signature, key []byte) error
 	// Name returns the name of the algorithm
 	Name() string
 }
 
+// KeyHasher is a Hasher that also accepts keys parsed ahead of time, so PEM
+// and JWK material is not decoded again for every token. All hashers
+// returned by NewHasher implement it.
+type KeyHasher interface {
+	Hasher
+	// ParseSigningKey parses key material for SignWithKey
+	ParseSigningKey(key []byte) (crypto.PrivateKey, error)
+	// ParseVerificationKey parses key material for VerifyWithKey
+	ParseVerificationKey(key []byte) (crypto.PublicKey, error)
+	// SignWithKey creates a signature with a parsed key. Asymmetric
+	// algorithms also accept any crypto.Signer, so keys held by a KMS or HSM
+	// can sign without being exported.
+	SignWithKey(data []byte, key crypto.PrivateKey) ([]byte, error)
+	// VerifyWithKey checks a signature with a parsed key
+	VerifyWithKey(data, signature []byte, key crypto.PublicKey) error
+}
+
+// HMACKey is the parsed form of an HMAC secret
+type HMACKey = hash.HMACKey
+
 // NewHasher creates a new hasher instance for the specified algorithm
 func NewHasher(algorithm Algorithm) (Hasher, error) {
 	switch algorithm {
@@ -41,7 +80,26 @@ func NewHasher(algorithm Algorithm) (Hasher, error) {
 		return &hash.HS512Hasher{}, nil
 	case RS256:
 		return &hash.RS256Hasher{}, nil
+	case PS256:
+		return &hash.PS256Hasher{}, nil
+	case ES256:
+		return &hash.ES256Hasher{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+}
+
+// MinKeySize returns the shortest key accepted for signing with an HMAC
+// algorithm (RFC 7518 section 3.2), or 0 for other algorithms
+func MinKeySize(algorithm Algorithm) int {
+	switch algorithm {
+	case HS256:
+		return hash.HS256MinKeySize
+	case HS384:
+		return hash.HS384MinKeySize
+	case HS512:
+		return hash.HS512MinKeySize
 	default:
-		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+		return 0
 	}
 }
@@ -3,8 +3,8 @@ package hash
 // MockHasher is a mock implementation of the Hasher interface for testing
 type MockHasher struct {
 	NameFunc   func() string
-	SignFunc   func(data []byte, key []byte) string
-	VerifyFunc func(data []byte, signature string, key []byte) bool
+	SignFunc   func(data, key []byte) ([]byte, error)
+	VerifyFunc func(data, signature, key []byte) error
 }
 
 // Name returns the name of the algorithm
@@ -16,17 +16,17 @@ func (m *MockHasher) Name() string {
 }
 
 // Sign creates a signature for the given data using the provided key
-func (m *MockHasher) Sign(data []byte, key []byte) string {
+func (m *MockHasher) Sign(data, key []byte) ([]byte, error) {
 	if m.SignFunc != nil {
 		return m.SignFunc(data, key)
 	}
-	return "mock-signature"
+	return []byte("mock-signature"), nil
 }
 
 // Verify checks if the signature is valid for the given data and key
-func (m *MockHasher) Verify(data []byte, signature string, key []byte) bool {
+func (m *MockHasher) Verify(data, signature, key []byte) error {
 	if m.VerifyFunc != nil {
 		return m.VerifyFunc(data, signature, key)
 	}
-	return true
+	return nil
 }
@@ -0,0 +1,125 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RegisteredClaims holds the claims registered by RFC 7519. Embed it in an
+// application claims struct to decode the payload with UnmarshalClaims.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// NumericDate is a JSON number of seconds since the epoch, possibly with a
+// fractional part
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate wraps t, truncated to microseconds
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{t.Truncate(time.Microsecond)}
+}
+
+// MarshalJSON renders the date as whole seconds, keeping any fraction
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	micros := d.UnixMicro()
+	if micros%1e6 == 0 {
+		return strconv.AppendInt(nil, micros/1e6, 10), nil
+	}
+	return strconv.AppendFloat(nil, float64(micros)/1e6, 'f', -1, 64), nil
+}
+
+// UnmarshalJSON accepts integer and fractional numbers; strings are rejected
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil || data[0] == '"' {
+		return fmt.Errorf("invalid NumericDate: expected a number, got %s", data)
+	}
+	if seconds, err := number.Int64(); err == nil {
+		d.Time = time.Unix(seconds, 0)
+		return nil
+	}
+	value, err := number.Float64()
+	if err != nil || math.IsInf(value, 0) {
+		return fmt.Errorf("invalid NumericDate: %s is out of range", data)
+	}
+	whole, frac := math.Modf(value)
+	d.Time = time.Unix(int64(whole), int64(math.Round(frac*1e6))*int64(time.Microsecond))
+	return nil
+}
+
+// Audience is the aud claim, which RFC 7519 allows to be a single string or
+// an array of strings
+type Audience []string
+
+// Contains reports whether aud is one of the audiences
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON renders a single audience as a string and others as an array
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON accepts a string, an array of strings or null
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*a = nil
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("invalid audience: expected a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+// UnmarshalClaims decodes the token's payload into a new T, typically a
+// struct embedding RegisteredClaims
+func UnmarshalClaims[T any](token *Token) (*T, error) {
+	parts := strings.Split(token.Raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT format: expected 3 parts, got %d", len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT format: payload is not valid base64")
+	}
+	claims := new(T)
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("failed to decode claims: %w", err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,119 @@
+package jwt_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+type appClaims struct {
+	jwt.RegisteredClaims
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+}
+
+func tokenWithPayload(payload string) *jwt.Token {
+	return &jwt.Token{Raw: "e30." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"}
+}
+
+func TestUnmarshalClaims(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		wantErr  bool
+		audience jwt.Audience
+		exp      time.Time
+	}{
+		{
+			name:     "String audience",
+			payload:  `{"iss":"issuer","sub":"user","aud":"api","exp":1700000000,"jti":"id-1","name":"Ann","roles":["admin"]}`,
+			audience: jwt.Audience{"api"},
+			exp:      time.Unix(1700000000, 0),
+		},
+		{
+			name:     "Array audience and fractional exp",
+			payload:  `{"aud":["api","web"],"exp":1700000000.25}`,
+			audience: jwt.Audience{"api", "web"},
+			exp:      time.Unix(1700000000, 250000000),
+		},
+		{
+			name:    "Null claims",
+			payload: `{"aud":null,"exp":null}`,
+		},
+		{
+			name:    "String exp",
+			payload: `{"exp":"1700000000"}`,
+			wantErr: true,
+		},
+		{
+			name:    "Mixed audience array",
+			payload: `{"aud":["api",1]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := jwt.UnmarshalClaims[appClaims](tokenWithPayload(tt.payload))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got claims %+v", claims)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(claims.Audience) != len(tt.audience) {
+				t.Errorf("Expected audience %v, got %v", tt.audience, claims.Audience)
+			}
+			for _, aud := range tt.audience {
+				if !claims.Audience.Contains(aud) {
+					t.Errorf("Expected audience to contain %q, got %v", aud, claims.Audience)
+				}
+			}
+			if tt.exp.IsZero() {
+				if claims.ExpiresAt != nil && !claims.ExpiresAt.IsZero() {
+					t.Errorf("Expected no exp, got %v", claims.ExpiresAt)
+				}
+			} else if claims.ExpiresAt == nil || !claims.ExpiresAt.Equal(tt.exp) {
+				t.Errorf("Expected exp %v, got %v", tt.exp, claims.ExpiresAt)
+			}
+		})
+	}
+
+	claims, _ := jwt.UnmarshalClaims[appClaims](tokenWithPayload(tests[0].payload))
+	if claims.Issuer != "issuer" || claims.Subject != "user" || claims.ID != "id-1" || claims.Name != "Ann" || len(claims.Roles) != 1 {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+}
+
+func TestRegisteredClaims_MarshalJSON(t *testing.T) {
+	claims := jwt.RegisteredClaims{
+		Subject:   "user",
+		Audience:  jwt.Audience{"api"},
+		ExpiresAt: jwt.NewNumericDate(time.Unix(1700000000, 0)),
+		IssuedAt:  jwt.NewNumericDate(time.Unix(1699999000, 500000000)),
+	}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"sub":"user","aud":"api","exp":1700000000,"iat":1699999000.5}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	claims.Audience = jwt.Audience{"api", "web"}
+	data, _ = json.Marshal(claims)
+	var decoded jwt.RegisteredClaims
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Audience) != 2 || !decoded.IssuedAt.Equal(claims.IssuedAt.Time) {
+		t.Errorf("Round trip mismatch: %s -> %+v", data, decoded)
+	}
+}
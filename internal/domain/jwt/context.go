@@ -0,0 +1,17 @@
+package jwt
+
+import "context"
+
+// contextKey is the unexported context key type for this package
+type contextKey struct{}
+
+// NewContext returns a context carrying a verified token
+func NewContext(ctx context.Context, token *Token) context.Context {
+	return context.WithValue(ctx, contextKey{}, token)
+}
+
+// FromContext returns the verified token stored in the context
+func FromContext(ctx context.Context) (*Token, bool) {
+	token, ok := ctx.Value(contextKey{}).(*Token)
+	return token, ok
+}
@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrCriticalHeader is returned for a malformed crit header or one listing an
+// extension the recipient does not understand
+var ErrCriticalHeader = errors.New("invalid crit header")
+
+// registeredHeaders are the header parameters defined by RFC 7515 itself,
+// which crit must not list
+var registeredHeaders = map[string]bool{
+	"alg": true, "jku": true, "jwk": true, "kid": true,
+	"x5u": true, "x5c": true, "x5t": true, "x5t#S256": true,
+	"typ": true, "cty": true, "crit": true,
+}
+
+// Extensions is a registry of header extensions the application
+// understands. Tokens whose crit header (RFC 7515 section 4.1.11) lists an
+// extension missing from the registry are rejected. It is safe for
+// concurrent use.
+type Extensions struct {
+	mu    sync.RWMutex
+	names map[string]bool
+}
+
+// DefaultExtensions is the registry used by verifiers that are not given
+// one. It starts empty; register the extensions your code processes.
+var DefaultExtensions = NewExtensions()
+
+// NewExtensions creates a registry understanding the given extensions. It
+// panics on names Register would reject, as they are programming errors.
+func NewExtensions(names ...string) *Extensions {
+	e := &Extensions{names: make(map[string]bool)}
+	for _, name := range names {
+		if err := e.Register(name); err != nil {
+			panic(err)
+		}
+	}
+	return e
+}
+
+// Register marks a header extension as understood. Header parameters
+// defined by RFC 7515 cannot be registered, since crit must not list them.
+func (e *Extensions) Register(name string) error {
+	if name == "" {
+		return fmt.Errorf("extension name must not be empty")
+	}
+	if registeredHeaders[name] {
+		return fmt.Errorf("%q is a registered header parameter, not an extension", name)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.names[name] = true
+	return nil
+}
+
+// Understands reports whether the extension is registered
+func (e *Extensions) Understands(name string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.names[name]
+}
+
+// Check validates the header's crit parameter. It must be a non-empty array
+// of distinct strings naming extensions that are registered, are not
+// defined by RFC 7515 and are present in the header. Headers without crit
+// pass.
+func (e *Extensions) Check(header map[string]any) error {
+	raw, ok := header["crit"]
+	if !ok {
+		return nil
+	}
+	names, ok := raw.([]any)
+	if !ok {
+		return fmt.Errorf("%w: crit must be an array of strings", ErrCriticalHeader)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("%w: crit must not be empty", ErrCriticalHeader)
+	}
+
+	seen := make(map[string]bool, len(names))
+	for _, entry := range names {
+		name, ok := entry.(string)
+		if !ok || name == "" {
+			return fmt.Errorf("%w: crit must contain only non-empty strings", ErrCriticalHeader)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: %q is listed more than once", ErrCriticalHeader, name)
+		}
+		seen[name] = true
+		if registeredHeaders[name] {
+			return fmt.Errorf("%w: %q is a registered header parameter", ErrCriticalHeader, name)
+		}
+		if !e.Understands(name) {
+			return fmt.Errorf("%w: unsupported critical extension %q", ErrCriticalHeader, name)
+		}
+		if _, ok := header[name]; !ok {
+			return fmt.Errorf("%w: critical extension %q is missing from the header", ErrCriticalHeader, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+
+	"jwt/internal/domain/jwt"
+)
+
+func TestExtensions_Check(t *testing.T) {
+	extensions := jwt.NewExtensions("b64", "https://example.com/tenant")
+
+	tests := []struct {
+		name    string
+		header  map[string]any
+		wantErr bool
+	}{
+		{"No crit", map[string]any{"alg": "HS256"}, false},
+		{"Understood extension", map[string]any{"alg": "HS256", "crit": []any{"b64"}, "b64": false}, false},
+		{"Two understood extensions", map[string]any{"crit": []any{"b64", "https://example.com/tenant"}, "b64": false, "https://example.com/tenant": "t1"}, false},
+		{"Empty", map[string]any{"crit": []any{}}, true},
+		{"Not an array", map[string]any{"crit": "b64", "b64": false}, true},
+		{"Null", map[string]any{"crit": nil}, true},
+		{"Non-string entry", map[string]any{"crit": []any{42}}, true},
+		{"Empty name", map[string]any{"crit": []any{""}, "": 1}, true},
+		{"Duplicate", map[string]any{"crit": []any{"b64", "b64"}, "b64": false}, true},
+		{"Registered name", map[string]any{"alg": "HS256", "crit": []any{"alg"}}, true},
+		{"Registered thumbprint name", map[string]any{"crit": []any{"x5t#S256"}, "x5t#S256": "abc"}, true},
+		{"crit itself", map[string]any{"crit": []any{"crit"}}, true},
+		{"Unknown extension", map[string]any{"crit": []any{"exp"}, "exp": 1}, true},
+		{"Understood but absent", map[string]any{"crit": []any{"b64"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := extensions.Check(tt.header)
+			if tt.wantErr && !errors.Is(err, jwt.ErrCriticalHeader) {
+				t.Errorf("Expected ErrCriticalHeader, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtensions_Register(t *testing.T) {
+	extensions := jwt.NewExtensions()
+	if extensions.Understands("b64") {
+		t.Error("Expected a new registry to be empty")
+	}
+	if err := extensions.Register("b64"); err != nil || !extensions.Understands("b64") {
+		t.Errorf("Expected b64 to be registered, got %v", err)
+	}
+	for _, name := range []string{"", "alg", "kid", "crit", "x5t#S256"} {
+		if err := extensions.Register(name); err == nil {
+			t.Errorf("Expected Register(%q) to fail", name)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected NewExtensions to panic on a registered header name")
+		}
+	}()
+	jwt.NewExtensions("typ")
+}
@@ -10,6 +10,10 @@ import (
 	"time"
 )
 
+// TestSecretKey is the well-known secret test tokens are signed with. It is
+// long enough for every HMAC algorithm.
+const TestSecretKey = "your-super-secret-key-123!@#$%^&*()-long-enough-for-hs512-signing!!"
+
 // Decoder defines the interface for JWT token decoding operations
 type Decoder interface {
 	// Decode decodes a JWT token and returns the decoded parts
@@ -41,49 +45,40 @@ func (d *DecoderImpl) Decode(token string, validate bool) (string, error) {
 		return "", fmt.Errorf("invalid JWT format: expected 3 parts, got %d", len(parts))
 	}
 
-	// Validate each part is valid base64
+	// Decode each part once, checking it is valid base64
+	var decoded [3][]byte
 	for i, part := range parts {
-		if _, err := base64.RawURLEncoding.DecodeString(part); err != nil {
+		b, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
 			return "", fmt.Errorf("invalid JWT format: part %d is not valid base64", i+1)
 		}
 		if part == "" {
 			return "", fmt.Errorf("invalid JWT format: part %d is empty", i+1)
 		}
+		decoded[i] = b
 	}
+	header, payload, signature := decoded[0], decoded[1], decoded[2]
 
-	// Parse header to get algorithm
-	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	headerMap, err := ParseObject(header)
 	if err != nil {
-		return "", fmt.Errorf("invalid JWT format: header is not valid base64")
-	}
-
-	var headerMap map[string]any
-	if err := json.Unmarshal(header, &headerMap); err != nil {
 		return "", fmt.Errorf("invalid JWT format: header is not valid JSON")
 	}
 
 	// Verify algorithm matches
 	if alg, ok := headerMap["alg"].(string); !ok || alg != d.hasher.Name() {
-		return "", fmt.Errorf("unsupported algorithm: %v", headerMap["alg"])
-	}
-
-	// Decode payload
-	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
-	if err != nil {
-		return "", fmt.Errorf("invalid JWT format: payload is not valid base64")
+		return "", fmt.Errorf("%w: %v", hash.ErrUnsupportedAlgorithm, headerMap["alg"])
 	}
 
 	// Format header and payload JSON with indentation
 	var headerFormatted, payloadFormatted []byte
-	if headerFormatted, err = json.MarshalIndent(headerMap, "", "  "); err != nil {
+	if headerFormatted, err = Indent(header); err != nil {
 		return "", fmt.Errorf("error formatting header JSON: %v", err)
 	}
 
-	var payloadMap map[string]any
-	if err := json.Unmarshal(payload, &payloadMap); err != nil {
+	if _, err := ParseObject(payload); err != nil {
 		return "", fmt.Errorf("invalid JWT format: payload is not valid JSON")
 	}
-	if payloadFormatted, err = json.MarshalIndent(payloadMap, "", "  "); err != nil {
+	if payloadFormatted, err = Indent(payload); err != nil {
 		return "", fmt.Errorf("error formatting payload JSON: %v", err)
 	}
 
@@ -108,8 +103,8 @@ func (d *DecoderImpl) Decode(token string, validate bool) (string, error) {
 		}
 
 		signatureInput := parts[0] + "." + parts[1]
-		if !d.hasher.Verify([]byte(signatureInput), parts[2], secretKey) {
-			return "", fmt.Errorf("invalid signature")
+		if err := d.hasher.Verify([]byte(signatureInput), signature, secretKey); err != nil {
+			return "", err
 		}
 		outputBuilder.WriteString("\nSignature: Valid")
 	}
@@ -158,11 +153,13 @@ func (d *DecoderImpl) GenerateTestToken(algorithm hash.Algorithm) (string, error
 	payloadBase64 := base64.RawURLEncoding.EncodeToString(payloadJSON)
 
 	// Create signature
-	secretKey := "your-super-secret-key-123!@#$%^&*()"
 	signatureInput := headerBase64 + "." + payloadBase64
-	signature := hasher.Sign([]byte(signatureInput), []byte(secretKey))
+	signature, err := hasher.Sign([]byte(signatureInput), []byte(TestSecretKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign test token: %w", err)
+	}
 
 	// Combine all parts
-	token := fmt.Sprintf("%s.%s.%s", headerBase64, payloadBase64, signature)
+	token := fmt.Sprintf("%s.%s.%s", headerBase64, payloadBase64, base64.RawURLEncoding.EncodeToString(signature))
 	return token, nil
 }
@@ -192,8 +192,11 @@ func TestDecoder(t *testing.T) {
 		NameFunc: func() string {
 			return "HS256"
 		},
-		VerifyFunc: func(data []byte, signature string, key []byte) bool {
-			return signature == "SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+		VerifyFunc: func(data, signature, key []byte) error {
+			if base64.RawURLEncoding.EncodeToString(signature) != "SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c" {
+				return hash.ErrSignatureInvalid
+			}
+			return nil
 		},
 	}
 	decoder := jwt.NewDecoder(mockHasher)
@@ -0,0 +1,138 @@
+package jwt
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ChangeKind classifies a difference between two claim sets
+type ChangeKind string
+
+// Supported change kinds
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change is a single difference at a path such as "roles[1]" or
+// "metadata.location"
+type Change struct {
+	Path string
+	Kind ChangeKind
+	Old  any
+	New  any
+}
+
+// Diff compares two JSON objects and lists their differences path by path,
+// descending into nested objects and arrays. Numbers are compared by value,
+// so 1700000000 and 1700000000.0 are equal.
+func Diff(a, b map[string]any) []Change {
+	var changes []Change
+	diffObjects("", a, b, &changes)
+	return changes
+}
+
+// diffValue compares two values at path
+func diffValue(path string, a, b any, changes *[]Change) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			diffObjects(path, a, b, changes)
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			diffArrays(path, a, b, changes)
+			return
+		}
+	}
+	if !equalValues(a, b) {
+		*changes = append(*changes, Change{Path: path, Kind: Changed, Old: a, New: b})
+	}
+}
+
+// diffObjects compares two objects member by member in sorted order
+func diffObjects(path string, a, b map[string]any, changes *[]Change) {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := name
+		if path != "" {
+			child = path + "." + name
+		}
+		av, inA := a[name]
+		bv, inB := b[name]
+		switch {
+		case !inB:
+			*changes = append(*changes, Change{Path: child, Kind: Removed, Old: av})
+		case !inA:
+			*changes = append(*changes, Change{Path: child, Kind: Added, New: bv})
+		default:
+			diffValue(child, av, bv, changes)
+		}
+	}
+}
+
+// diffArrays compares two arrays index by index
+func diffArrays(path string, a, b []any, changes *[]Change) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		child := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(b):
+			*changes = append(*changes, Change{Path: child, Kind: Removed, Old: a[i]})
+		case i >= len(a):
+			*changes = append(*changes, Change{Path: child, Kind: Added, New: b[i]})
+		default:
+			diffValue(child, a[i], b[i], changes)
+		}
+	}
+}
+
+// equalValues compares scalars, treating numbers of any representation by
+// value
+func equalValues(a, b any) bool {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// number converts json.Number and float64 values for comparison
+func number(v any) (string, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return strconv.FormatInt(i, 10), true
+		}
+		if f, err := v.Float64(); err == nil {
+			return normalizeFloat(f), true
+		}
+		return v.String(), true
+	case float64:
+		return normalizeFloat(v), true
+	}
+	return "", false
+}
+
+// normalizeFloat renders integral floats like integers
+func normalizeFloat(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,55 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"jwt/internal/domain/jwt"
+)
+
+func TestDiff(t *testing.T) {
+	a := map[string]any{
+		"sub":   "alice",
+		"exp":   json.Number("1700000000"),
+		"roles": []any{"user", "admin"},
+		"meta":  map[string]any{"team": "core", "location": "HQ"},
+		"old":   true,
+	}
+	b := map[string]any{
+		"sub":   "alice",
+		"exp":   json.Number("1700003600"),
+		"roles": []any{"user"},
+		"meta":  map[string]any{"team": "edge", "location": "HQ", "floor": float64(3)},
+		"new":   "yes",
+	}
+
+	want := []jwt.Change{
+		{Path: "exp", Kind: jwt.Changed, Old: json.Number("1700000000"), New: json.Number("1700003600")},
+		{Path: "meta.floor", Kind: jwt.Added, New: float64(3)},
+		{Path: "meta.team", Kind: jwt.Changed, Old: "core", New: "edge"},
+		{Path: "new", Kind: jwt.Added, New: "yes"},
+		{Path: "old", Kind: jwt.Removed, Old: true},
+		{Path: "roles[1]", Kind: jwt.Removed, Old: "admin"},
+	}
+	if got := jwt.Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff mismatch:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestDiff_NumbersByValue(t *testing.T) {
+	a := map[string]any{"exp": json.Number("1700000000"), "id": json.Number("1234567890123456789")}
+	b := map[string]any{"exp": json.Number("1700000000.0"), "id": json.Number("1234567890123456788")}
+
+	changes := jwt.Diff(a, b)
+	if len(changes) != 1 || changes[0].Path != "id" {
+		t.Errorf("Expected only the large integer to differ, got %+v", changes)
+	}
+}
+
+func TestDiff_TypeChange(t *testing.T) {
+	changes := jwt.Diff(map[string]any{"aud": "api"}, map[string]any{"aud": []any{"api"}})
+	if len(changes) != 1 || changes[0].Kind != jwt.Changed {
+		t.Errorf("Expected a single change for a type change, got %+v", changes)
+	}
+}
@@ -0,0 +1,7 @@
+package jwt
+
+// Encoder defines the interface for creating signed JWT tokens
+type Encoder interface {
+	// Encode signs the claims and returns a compact serialized token
+	Encode(claims map[string]any) (string, error)
+}
@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoActiveKey is returned when no keyring key is usable at the current time
+var ErrNoActiveKey = errors.New("no active key")
+
+// SigningKeySource supplies the key used to sign new tokens
+type SigningKeySource interface {
+	// SigningKey returns the key ID and key material to sign with
+	SigningKey() (kid string, key []byte, err error)
+}
+
+// RingKey is a keyring entry. NotBefore and NotAfter, when set, bound the
+// period in which the key is accepted; NotAfter is exclusive.
+type RingKey struct {
+	ID        string    `json:"kid"`
+	Key       string    `json:"key"`
+	NotBefore time.Time `json:"not_before,omitzero"`
+	NotAfter  time.Time `json:"not_after,omitzero"`
+}
+
+// Active reports whether the key is within its validity window at t
+func (k RingKey) Active(t time.Time) bool {
+	if !k.NotBefore.IsZero() && t.Before(k.NotBefore) {
+		return false
+	}
+	if !k.NotAfter.IsZero() && !t.Before(k.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// Keyring holds several keys during rotation. It is a KeySource that selects
+// the key named by the token's kid header, or offers every active key in
+// order when there is none, and a SigningKeySource for the Current key.
+type Keyring struct {
+	// Current is the ID of the key new tokens are signed with
+	Current string `json:"current"`
+	// Keys lists the keys in the order they are tried
+	Keys []RingKey `json:"keys"`
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time `json:"-"`
+}
+
+// Validate checks that key IDs are unique and non-empty, every key has key
+// material and the current key exists
+func (r *Keyring) Validate() error {
+	seen := make(map[string]bool, len(r.Keys))
+	for i, k := range r.Keys {
+		if k.ID == "" {
+			return fmt.Errorf("invalid keyring: key %d has no kid", i+1)
+		}
+		if seen[k.ID] {
+			return fmt.Errorf("invalid keyring: duplicate kid %q", k.ID)
+		}
+		if k.Key == "" {
+			return fmt.Errorf("invalid keyring: key %q is empty", k.ID)
+		}
+		if !k.NotBefore.IsZero() && !k.NotAfter.IsZero() && !k.NotBefore.Before(k.NotAfter) {
+			return fmt.Errorf("invalid keyring: key %q has an empty validity window", k.ID)
+		}
+		seen[k.ID] = true
+	}
+	if r.Current != "" && !seen[r.Current] {
+		return fmt.Errorf("invalid keyring: current key %q is not in the keyring", r.Current)
+	}
+	return nil
+}
+
+// VerificationKeys returns the key matching the header's kid, or all active
+// keys in order when the token has no kid
+func (r *Keyring) VerificationKeys(header map[string]any) ([][]byte, error) {
+	now := r.now()
+	if kid, ok := header["kid"].(string); ok && kid != "" {
+		for _, k := range r.Keys {
+			if k.ID != kid {
+				continue
+			}
+			if !k.Active(now) {
+				return nil, fmt.Errorf("%w: key %q is outside its validity window", ErrNoActiveKey, kid)
+			}
+			return [][]byte{[]byte(k.Key)}, nil
+		}
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+
+	var keys [][]byte
+	for _, k := range r.Keys {
+		if k.Active(now) {
+			keys = append(keys, []byte(k.Key))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoActiveKey
+	}
+	return keys, nil
+}
+
+// SigningKey returns the current key, which must be within its window
+func (r *Keyring) SigningKey() (string, []byte, error) {
+	if r.Current == "" {
+		return "", nil, fmt.Errorf("%w: the keyring has no current key", ErrNoActiveKey)
+	}
+	for _, k := range r.Keys {
+		if k.ID != r.Current {
+			continue
+		}
+		if !k.Active(r.now()) {
+			return "", nil, fmt.Errorf("%w: current key %q is outside its validity window", ErrNoActiveKey, k.ID)
+		}
+		return k.ID, []byte(k.Key), nil
+	}
+	return "", nil, fmt.Errorf("%w: current key %q is not in the keyring", ErrNoActiveKey, r.Current)
+}
+
+// now returns the keyring's notion of the current time
+func (r *Keyring) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,79 @@
+package jwt_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+func TestKeyring(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ring := &jwt.Keyring{
+		Current: "new",
+		Keys: []jwt.RingKey{
+			{ID: "new", Key: "new-secret", NotBefore: now.Add(-time.Hour)},
+			{ID: "old", Key: "old-secret", NotAfter: now.Add(time.Hour)},
+			{ID: "next", Key: "next-secret", NotBefore: now.Add(time.Hour)},
+		},
+		Now: func() time.Time { return now },
+	}
+	if err := ring.Validate(); err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	keys, err := ring.VerificationKeys(map[string]any{})
+	if err != nil || len(keys) != 2 || string(keys[0]) != "new-secret" || string(keys[1]) != "old-secret" {
+		t.Errorf("Expected active keys in order, got %q, %v", keys, err)
+	}
+	keys, err = ring.VerificationKeys(map[string]any{"kid": "old"})
+	if err != nil || len(keys) != 1 || string(keys[0]) != "old-secret" {
+		t.Errorf("Expected key selected by kid, got %q, %v", keys, err)
+	}
+	if _, err := ring.VerificationKeys(map[string]any{"kid": "next"}); !errors.Is(err, jwt.ErrNoActiveKey) {
+		t.Errorf("Expected ErrNoActiveKey for a key not valid yet, got %v", err)
+	}
+	if _, err := ring.VerificationKeys(map[string]any{"kid": "missing"}); err == nil {
+		t.Error("Expected error for unknown kid")
+	}
+
+	kid, key, err := ring.SigningKey()
+	if err != nil || kid != "new" || string(key) != "new-secret" {
+		t.Errorf("Expected current key to sign, got %q %q %v", kid, key, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	keys, _ = ring.VerificationKeys(map[string]any{})
+	if len(keys) != 2 || string(keys[1]) != "next-secret" {
+		t.Errorf("Expected retired key to drop out and next key to activate, got %q", keys)
+	}
+	ring.Current = "old"
+	if _, _, err := ring.SigningKey(); !errors.Is(err, jwt.ErrNoActiveKey) {
+		t.Errorf("Expected expired current key to be refused, got %v", err)
+	}
+}
+
+func TestKeyring_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		ring        jwt.Keyring
+		errContains string
+	}{
+		{"Missing kid", jwt.Keyring{Keys: []jwt.RingKey{{Key: "a"}}}, "has no kid"},
+		{"Duplicate kid", jwt.Keyring{Keys: []jwt.RingKey{{ID: "a", Key: "a"}, {ID: "a", Key: "b"}}}, "duplicate kid"},
+		{"Empty key", jwt.Keyring{Keys: []jwt.RingKey{{ID: "a"}}}, "is empty"},
+		{"Unknown current", jwt.Keyring{Current: "b", Keys: []jwt.RingKey{{ID: "a", Key: "a"}}}, "not in the keyring"},
+		{"Empty window", jwt.Keyring{Keys: []jwt.RingKey{{ID: "a", Key: "a", NotBefore: time.Unix(2, 0), NotAfter: time.Unix(1, 0)}}}, "empty validity window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ring.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error to contain %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
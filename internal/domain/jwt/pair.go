@@ -0,0 +1,40 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+)
+
+// Errors reported by a RefreshStore
+var (
+	// ErrRefreshReused is returned when an already exchanged refresh token is
+	// presented again, which indicates it was leaked
+	ErrRefreshReused = errors.New("refresh token reuse detected")
+	// ErrUnknownRefresh is returned for refresh tokens the store never issued
+	// or has already forgotten
+	ErrUnknownRefresh = errors.New("unknown refresh token")
+)
+
+// TokenPair is a short-lived access token with the refresh token that
+// renews it, shaped like an OAuth 2.0 token response
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// RefreshStore tracks issued refresh tokens. Tokens renewed from one another
+// form a family, which is revoked as a whole when reuse is detected.
+// Implementations must be safe for concurrent use.
+type RefreshStore interface {
+	// Add records a newly issued refresh token
+	Add(family, jti string, expiresAt time.Time) error
+	// Use marks a refresh token as exchanged. It returns ErrRefreshReused
+	// when the token was already exchanged, ErrRevoked when its family was
+	// revoked and ErrUnknownRefresh when the token is not recorded.
+	Use(family, jti string) error
+	// RevokeFamily invalidates every token of the family
+	RevokeFamily(family string) error
+}
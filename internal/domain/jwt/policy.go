@@ -0,0 +1,98 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrTokenExpired is returned for tokens past their exp claim
+var ErrTokenExpired = errors.New("token is expired")
+
+// Policy describes the claim checks applied when a token is validated
+type Policy struct {
+	// Issuer, when set, must match the iss claim exactly
+	Issuer string
+	// Audience, when set, must be contained in the aud claim
+	Audience string
+	// Leeway allows for clock skew when checking exp and nbf
+	Leeway time.Duration
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks the registered claims against the policy
+func (p Policy) Validate(claims map[string]any) error {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+
+	if exp, ok, err := ClaimTime(claims, "exp"); err != nil {
+		return err
+	} else if ok && !now.Before(exp.Add(p.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	if nbf, ok, err := ClaimTime(claims, "nbf"); err != nil {
+		return err
+	} else if ok && now.Add(p.Leeway).Before(nbf) {
+		return fmt.Errorf("token is not valid yet")
+	}
+
+	if p.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != p.Issuer {
+			return fmt.Errorf("invalid issuer: %v", claims["iss"])
+		}
+	}
+
+	if p.Audience != "" && !hasAudience(claims["aud"], p.Audience) {
+		return fmt.Errorf("invalid audience: %v", claims["aud"])
+	}
+
+	return nil
+}
+
+// ClaimTime reads a NumericDate claim (seconds since the epoch), decoded
+// either as json.Number or float64. It reports false for an absent claim.
+func ClaimTime(claims map[string]any, name string) (time.Time, bool, error) {
+	value, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	var seconds float64
+	switch value := value.(type) {
+	case json.Number:
+		if whole, err := value.Int64(); err == nil {
+			return time.Unix(whole, 0), true, nil
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s claim: %s is out of range", name, value)
+		}
+		seconds = f
+	case float64:
+		seconds = value
+	default:
+		return time.Time{}, false, fmt.Errorf("invalid %s claim: expected a number", name)
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second))), true, nil
+}
+
+// hasAudience reports whether the aud claim (string or array) contains want
+func hasAudience(aud any, want string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == want
+	case []any:
+		for _, a := range aud {
+			if s, ok := a.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
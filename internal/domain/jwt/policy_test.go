@@ -0,0 +1,89 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	policy := jwt.Policy{
+		Issuer:   "https://issuer.example.com",
+		Audience: "api",
+		Leeway:   30 * time.Second,
+		Now:      func() time.Time { return now },
+	}
+
+	tests := []struct {
+		name        string
+		claims      map[string]any
+		errContains string
+	}{
+		{
+			name:   "Valid claims",
+			claims: map[string]any{"iss": "https://issuer.example.com", "aud": "api", "exp": float64(now.Unix() + 60)},
+		},
+		{
+			name:   "Audience array",
+			claims: map[string]any{"iss": "https://issuer.example.com", "aud": []any{"web", "api"}},
+		},
+		{
+			name:   "Expired within leeway",
+			claims: map[string]any{"iss": "https://issuer.example.com", "aud": "api", "exp": float64(now.Unix() - 10)},
+		},
+		{
+			name:        "Expired",
+			claims:      map[string]any{"iss": "https://issuer.example.com", "aud": "api", "exp": float64(now.Unix() - 60)},
+			errContains: "token is expired",
+		},
+		{
+			name:        "Expired json.Number",
+			claims:      map[string]any{"iss": "https://issuer.example.com", "aud": "api", "exp": json.Number(strconv.FormatInt(now.Unix()-60, 10))},
+			errContains: "token is expired",
+		},
+		{
+			name:   "Fractional json.Number",
+			claims: map[string]any{"iss": "https://issuer.example.com", "aud": "api", "exp": json.Number(strconv.FormatInt(now.Unix()+60, 10) + ".5")},
+		},
+		{
+			name:        "Not valid yet",
+			claims:      map[string]any{"iss": "https://issuer.example.com", "aud": "api", "nbf": float64(now.Unix() + 60)},
+			errContains: "token is not valid yet",
+		},
+		{
+			name:        "Wrong issuer",
+			claims:      map[string]any{"iss": "https://evil.example.com", "aud": "api"},
+			errContains: "invalid issuer",
+		},
+		{
+			name:        "Wrong audience",
+			claims:      map[string]any{"iss": "https://issuer.example.com", "aud": []any{"web"}},
+			errContains: "invalid audience",
+		},
+		{
+			name:        "Non-numeric exp",
+			claims:      map[string]any{"exp": "tomorrow"},
+			errContains: "invalid exp claim",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.claims)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error to contain %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrReplayed is returned when a token's jti has already been used
+var ErrReplayed = errors.New("token has already been used")
+
+// ReplayStore records the jti of accepted tokens so each token is accepted
+// only once. Implementations must be safe for concurrent use.
+type ReplayStore interface {
+	// Use records jti until expiresAt. It returns ErrReplayed when jti was
+	// already recorded and has not expired yet.
+	Use(jti string, expiresAt time.Time) error
+}
+
+// CheckReplay records the token's jti in the store, rejecting tokens that
+// were seen before. The jti is kept until exp plus leeway, after which the
+// token would be rejected as expired anyway.
+func CheckReplay(store ReplayStore, claims map[string]any, leeway time.Duration) error {
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return fmt.Errorf("invalid jti claim: replay protection requires a token ID")
+	}
+	exp, ok, err := ClaimTime(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("invalid exp claim: replay protection requires an expiration time")
+	}
+	return store.Use(jti, exp.Add(leeway))
+}
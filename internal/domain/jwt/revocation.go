@@ -0,0 +1,96 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrRevoked is returned for tokens matched by a revocation entry
+var ErrRevoked = errors.New("token has been revoked")
+
+// RevocationChecker decides whether a verified token has been revoked
+type RevocationChecker interface {
+	// CheckRevoked returns an error wrapping ErrRevoked for revoked tokens
+	CheckRevoked(token *Token) error
+}
+
+// Denylist lists revoked token IDs, subjects and signing keys
+type Denylist struct {
+	// JTIs revokes individual tokens by jti
+	JTIs []string `json:"jti,omitempty"`
+	// Subjects revokes every token for a subject issued at or before the
+	// cutoff, given in seconds since the epoch
+	Subjects map[string]int64 `json:"sub,omitempty"`
+	// KeyIDs revokes every token signed with the key
+	KeyIDs []string `json:"kid,omitempty"`
+}
+
+// CheckRevoked matches the token against the denylist. A token for a listed
+// subject without a usable iat claim is treated as revoked.
+func (d *Denylist) CheckRevoked(token *Token) error {
+	if jti, ok := token.Claims["jti"].(string); ok && slices.Contains(d.JTIs, jti) {
+		return fmt.Errorf("%w: jti %q", ErrRevoked, jti)
+	}
+	if kid, ok := token.Header["kid"].(string); ok && slices.Contains(d.KeyIDs, kid) {
+		return fmt.Errorf("%w: key %q", ErrRevoked, kid)
+	}
+	if sub, ok := token.Claims["sub"].(string); ok {
+		if cutoff, listed := d.Subjects[sub]; listed {
+			iat, ok, err := ClaimTime(token.Claims, "iat")
+			if err != nil || !ok || !iat.After(time.Unix(cutoff, 0)) {
+				return fmt.Errorf("%w: tokens for subject %q issued until %s", ErrRevoked, sub, time.Unix(cutoff, 0).UTC().Format(time.RFC3339))
+			}
+		}
+	}
+	return nil
+}
+
+// RevokeJTI adds a token ID, reporting whether it was not listed yet
+func (d *Denylist) RevokeJTI(jti string) bool {
+	if slices.Contains(d.JTIs, jti) {
+		return false
+	}
+	d.JTIs = append(d.JTIs, jti)
+	return true
+}
+
+// RevokeSubject revokes the subject's tokens issued at or before the given
+// time, replacing any earlier cutoff
+func (d *Denylist) RevokeSubject(sub string, before time.Time) {
+	if d.Subjects == nil {
+		d.Subjects = make(map[string]int64)
+	}
+	d.Subjects[sub] = before.Unix()
+}
+
+// RevokeKey adds a key ID, reporting whether it was not listed yet
+func (d *Denylist) RevokeKey(kid string) bool {
+	if slices.Contains(d.KeyIDs, kid) {
+		return false
+	}
+	d.KeyIDs = append(d.KeyIDs, kid)
+	return true
+}
+
+// RemoveJTI removes a token ID, reporting whether it was listed
+func (d *Denylist) RemoveJTI(jti string) bool {
+	n := len(d.JTIs)
+	d.JTIs = slices.DeleteFunc(d.JTIs, func(v string) bool { return v == jti })
+	return len(d.JTIs) != n
+}
+
+// RemoveSubject removes a subject cutoff, reporting whether it was listed
+func (d *Denylist) RemoveSubject(sub string) bool {
+	_, ok := d.Subjects[sub]
+	delete(d.Subjects, sub)
+	return ok
+}
+
+// RemoveKey removes a key ID, reporting whether it was listed
+func (d *Denylist) RemoveKey(kid string) bool {
+	n := len(d.KeyIDs)
+	d.KeyIDs = slices.DeleteFunc(d.KeyIDs, func(v string) bool { return v == kid })
+	return len(d.KeyIDs) != n
+}
@@ -0,0 +1,53 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+func TestDenylist_CheckRevoked(t *testing.T) {
+	cutoff := time.Unix(1700000000, 0)
+	list := &jwt.Denylist{}
+	list.RevokeJTI("stolen")
+	list.RevokeKey("compromised")
+	list.RevokeSubject("user-1", cutoff)
+
+	tests := []struct {
+		name    string
+		header  map[string]any
+		claims  map[string]any
+		revoked bool
+	}{
+		{"Listed jti", nil, map[string]any{"jti": "stolen"}, true},
+		{"Other jti", nil, map[string]any{"jti": "fine"}, false},
+		{"Listed kid", map[string]any{"kid": "compromised"}, map[string]any{}, true},
+		{"Subject issued before cutoff", nil, map[string]any{"sub": "user-1", "iat": json.Number("1699999999")}, true},
+		{"Subject issued at cutoff", nil, map[string]any{"sub": "user-1", "iat": float64(1700000000)}, true},
+		{"Subject issued after cutoff", nil, map[string]any{"sub": "user-1", "iat": json.Number("1700000001")}, false},
+		{"Subject without iat", nil, map[string]any{"sub": "user-1"}, true},
+		{"Other subject", nil, map[string]any{"sub": "user-2", "iat": float64(1)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := list.CheckRevoked(&jwt.Token{Header: tt.header, Claims: tt.claims})
+			if tt.revoked != errors.Is(err, jwt.ErrRevoked) {
+				t.Errorf("Expected revoked=%v, got %v", tt.revoked, err)
+			}
+		})
+	}
+
+	if !list.RemoveJTI("stolen") || list.RemoveJTI("stolen") {
+		t.Error("Expected RemoveJTI to report whether the entry was listed")
+	}
+	if !list.RemoveSubject("user-1") || !list.RemoveKey("compromised") {
+		t.Error("Expected listed entries to be removed")
+	}
+	if err := list.CheckRevoked(&jwt.Token{Claims: map[string]any{"jti": "stolen", "sub": "user-1"}}); err != nil {
+		t.Errorf("Expected no revocation after removal, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxTokenSize is the size limit strict parsing applies when none is
+// configured. It leaves room for an x5c chain of a few certificates.
+const DefaultMaxTokenSize = 16 << 10
+
+// Errors reported by strict parsing, one per rule
+var (
+	// ErrTokenTooLarge is returned for tokens above the size limit
+	ErrTokenTooLarge = errors.New("token exceeds the size limit")
+	// ErrNonCanonicalBase64 is returned for segments with padding, line
+	// breaks or non-zero trailing bits
+	ErrNonCanonicalBase64 = errors.New("non-canonical base64url")
+	// ErrDuplicateMember is returned for JSON objects repeating a member name
+	ErrDuplicateMember = errors.New("duplicate JSON member")
+)
+
+// strictEncoding rejects non-zero trailing bits
+var strictEncoding = base64.RawURLEncoding.Strict()
+
+// DecodeSegmentStrict decodes a base64url token segment, accepting only its
+// canonical form: no padding, no line breaks and zero trailing bits, so each
+// payload has exactly one encoding
+func DecodeSegmentStrict(segment string) ([]byte, error) {
+	if strings.IndexByte(segment, '=') >= 0 {
+		return nil, fmt.Errorf("%w: padding is not allowed", ErrNonCanonicalBase64)
+	}
+	// The decoder skips CR and LF even in strict mode
+	if strings.ContainsAny(segment, "\r\n") {
+		return nil, fmt.Errorf("%w: line breaks are not allowed", ErrNonCanonicalBase64)
+	}
+	decoded, err := strictEncoding.DecodeString(segment)
+	if err == nil {
+		return decoded, nil
+	}
+	if _, lax := base64.RawURLEncoding.DecodeString(segment); lax == nil {
+		return nil, fmt.Errorf("%w: trailing bits are not zero", ErrNonCanonicalBase64)
+	}
+	return nil, err
+}
+
+// CheckDuplicateMembers reports the first member name that repeats within
+// one object, at any depth. Names are compared after unescaping, so "a" and
+// "\u0061" are duplicates. Malformed JSON is left to ParseObject to report.
+func CheckDuplicateMembers(data []byte) error {
+	err := checkMembers(json.NewDecoder(bytes.NewReader(data)), "")
+	if errors.Is(err, ErrDuplicateMember) {
+		return err
+	}
+	return nil
+}
+
+// checkMembers walks one JSON value, tracking the member names of each
+// object; path locates the value for error messages
+func checkMembers(dec *json.Decoder, path string) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		seen := make(map[string]bool)
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			name, _ := token.(string)
+			memberPath := name
+			if path != "" {
+				memberPath = path + "." + name
+			}
+			if seen[name] {
+				return fmt.Errorf("%w %q", ErrDuplicateMember, memberPath)
+			}
+			seen[name] = true
+			if err := checkMembers(dec, memberPath); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if err := checkMembers(dec, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+	// Consume the closing delimiter
+	_, err = dec.Token()
+	return err
+}
@@ -0,0 +1,75 @@
+package jwt_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"jwt/internal/domain/jwt"
+)
+
+func TestDecodeSegmentStrict(t *testing.T) {
+	tests := []struct {
+		name        string
+		segment     string
+		want        string
+		wantErr     error
+		errContains string
+	}{
+		{name: "Canonical", segment: "eyJhIjoxfQ", want: `{"a":1}`},
+		{name: "Empty", segment: "", want: ""},
+		{name: "Padding", segment: "eyJhIjoxfQ==", wantErr: jwt.ErrNonCanonicalBase64, errContains: "padding"},
+		{name: "Line break", segment: "eyJhIjox\nfQ", wantErr: jwt.ErrNonCanonicalBase64, errContains: "line breaks"},
+		// "fR" and "fQ" both decode to "}" without strict trailing bit checks
+		{name: "Non-zero trailing bits", segment: "eyJhIjoxfR", wantErr: jwt.ErrNonCanonicalBase64, errContains: "trailing bits"},
+		{name: "Standard alphabet", segment: "ab+/", errContains: "illegal base64 data"},
+		{name: "Impossible length", segment: "eyJhI", errContains: "illegal base64 data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jwt.DecodeSegmentStrict(tt.segment)
+			if tt.errContains == "" {
+				if err != nil || string(got) != tt.want {
+					t.Errorf("Expected %q, got %q (%v)", tt.want, got, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckDuplicateMembers(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{name: "Distinct", json: `{"a":1,"b":{"a":2},"c":[{"a":3},{"a":4}]}`},
+		{name: "Top level", json: `{"sub":"alice","sub":"admin"}`, wantErr: `duplicate JSON member "sub"`},
+		{name: "Escaped name", json: `{"alg":"HS256","\u0061lg":"none"}`, wantErr: `duplicate JSON member "alg"`},
+		{name: "Nested", json: `{"a":{"b":1,"b":2}}`, wantErr: `duplicate JSON member "a.b"`},
+		{name: "In array", json: `{"a":[{"b":1},{"b":1,"b":2}]}`, wantErr: `duplicate JSON member "a[1].b"`},
+		{name: "Malformed", json: `{"a":`},
+		{name: "Not JSON", json: `not json`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := jwt.CheckDuplicateMembers([]byte(tt.json))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, jwt.ErrDuplicateMember) || err.Error() != tt.wantErr {
+				t.Errorf("Expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"errors"
+
+	"jwt/internal/domain/hash"
+)
+
+// ErrMalformedToken is returned for tokens that are not a well-formed
+// compact JWS
+var ErrMalformedToken = errors.New("invalid JWT format")
+
+// Describe maps a verification error to a fixed phrase that is safe to
+// return to whoever presented the token, hiding key lookup and claim details
+func Describe(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return "token expired"
+	case errors.Is(err, hash.ErrSignatureInvalid):
+		return "invalid signature"
+	case errors.Is(err, ErrMalformedToken), errors.Is(err, ErrTokenTooLarge):
+		return "malformed token"
+	default:
+		return "invalid token"
+	}
+}
+
+// Token is a parsed and validated JWT
+type Token struct {
+	// Raw is the compact serialized token
+	Raw string
+	// Header holds the JOSE header parameters
+	Header map[string]any
+	// Claims holds the payload claims
+	Claims map[string]any
+}
+
+// Verifier defines the interface for validating tokens
+type Verifier interface {
+	// Verify checks the token's signature and claims and returns its contents
+	Verify(token string) (*Token, error)
+}
+
+// Parser decodes tokens without verifying them
+type Parser interface {
+	// Parse returns the token's header and claims
+	Parse(token string) (*Token, error)
+}
+
+// KeySource supplies the keys used to verify token signatures
+type KeySource interface {
+	// VerificationKeys returns the candidate keys for a token header, in the
+	// order they should be tried
+	VerificationKeys(header map[string]any) ([][]byte, error)
+}
+
+// StaticKey is a KeySource that always returns the same key
+type StaticKey []byte
+
+// VerificationKeys returns the static key
+func (k StaticKey) VerificationKeys(header map[string]any) ([][]byte, error) {
+	return [][]byte{k}, nil
+}
@@ -0,0 +1,151 @@
+package jwt
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"jwt/internal/domain/key"
+)
+
+// ErrCertificateChain is returned when a token's x5c chain is missing,
+// malformed or not trusted
+var ErrCertificateChain = errors.New("invalid certificate chain")
+
+// CertificateChain is a KeySource that verifies tokens with the key of the
+// leaf certificate in their x5c header (RFC 7515 section 4.1.6). The chain
+// must lead to one of Roots, and x5t and x5t#S256 thumbprints, when present,
+// must match the leaf.
+type CertificateChain struct {
+	// Roots holds the trusted root CAs
+	Roots *x509.CertPool
+	// KeyUsages lists the extended key usages the leaf must allow; any
+	// usage is accepted when empty
+	KeyUsages []x509.ExtKeyUsage
+	// Now returns the time certificates are checked at; defaults to time.Now
+	Now func() time.Time
+}
+
+// VerificationKeys validates the header's certificate chain and returns the
+// leaf certificate as PEM, provided its key suits the header's alg
+func (c *CertificateChain) VerificationKeys(header map[string]any) ([][]byte, error) {
+	if c.Roots == nil {
+		return nil, fmt.Errorf("%w: no root CAs configured", ErrCertificateChain)
+	}
+
+	chain, err := parseX5C(header)
+	if err != nil {
+		return nil, err
+	}
+	leaf := chain[0]
+	if err := checkLeafAlgorithm(leaf, header); err != nil {
+		return nil, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	usages := c.KeyUsages
+	if len(usages) == 0 {
+		usages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         c.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   c.now(),
+		KeyUsages:     usages,
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCertificateChain, err)
+	}
+
+	sha1Sum := sha1.Sum(leaf.Raw)
+	if err := checkThumbprint(header, "x5t", sha1Sum[:]); err != nil {
+		return nil, err
+	}
+	sha256Sum := sha256.Sum256(leaf.Raw)
+	if err := checkThumbprint(header, "x5t#S256", sha256Sum[:]); err != nil {
+		return nil, err
+	}
+
+	return [][]byte{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})}, nil
+}
+
+// checkLeafAlgorithm rejects a leaf whose key cannot verify the header's alg
+func checkLeafAlgorithm(leaf *x509.Certificate, header map[string]any) error {
+	jwk, err := key.NewJWK(leaf.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCertificateChain, err)
+	}
+	alg, _ := header["alg"].(string)
+	if err := jwk.CheckAlgorithm(alg); err != nil {
+		return fmt.Errorf("%w: %w", ErrCertificateChain, err)
+	}
+	return nil
+}
+
+// now returns the chain's notion of the current time
+func (c *CertificateChain) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// parseX5C decodes the x5c header, a non-empty array of base64 (not
+// base64url) DER certificates starting with the leaf
+func parseX5C(header map[string]any) ([]*x509.Certificate, error) {
+	raw, ok := header["x5c"]
+	if !ok {
+		return nil, fmt.Errorf("%w: token has no x5c header", ErrCertificateChain)
+	}
+	entries, ok := raw.([]any)
+	if !ok || len(entries) == 0 {
+		return nil, fmt.Errorf("%w: x5c must be a non-empty array", ErrCertificateChain)
+	}
+
+	chain := make([]*x509.Certificate, 0, len(entries))
+	for i, entry := range entries {
+		encoded, ok := entry.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: x5c entry %d is not a string", ErrCertificateChain, i+1)
+		}
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: x5c entry %d is not valid base64", ErrCertificateChain, i+1)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("%w: x5c entry %d: %v", ErrCertificateChain, i+1, err)
+		}
+		chain = append(chain, cert)
+	}
+	return chain, nil
+}
+
+// checkThumbprint compares an optional base64url thumbprint header with the
+// leaf certificate's digest
+func checkThumbprint(header map[string]any, name string, sum []byte) error {
+	raw, ok := header[name]
+	if !ok {
+		return nil
+	}
+	encoded, ok := raw.(string)
+	if !ok {
+		return fmt.Errorf("%w: %s must be a string", ErrCertificateChain, name)
+	}
+	thumbprint, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%w: %s is not valid base64url", ErrCertificateChain, name)
+	}
+	if subtle.ConstantTimeCompare(thumbprint, sum) != 1 {
+		return fmt.Errorf("%w: %s does not match the leaf certificate", ErrCertificateChain, name)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+// issueCert creates a certificate for template signed by parent, or a
+// self-signed one when parent is nil
+func issueCert(t *testing.T, cn string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Unix(1700000000, 0),
+		NotAfter:              time.Unix(1700000000, 0).Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, privateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &privateKey.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, privateKey
+}
+
+func TestCertificateChain(t *testing.T) {
+	root, rootKey := issueCert(t, "Root CA", true, nil, nil)
+	intermediate, intermediateKey := issueCert(t, "Intermediate CA", true, root, rootKey)
+	leaf, _ := issueCert(t, "partner.example.com", false, intermediate, intermediateKey)
+	otherRoot, _ := issueCert(t, "Other CA", true, nil, nil)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	now := time.Unix(1700000000, 0).Add(time.Hour)
+
+	x5c := []any{
+		base64.StdEncoding.EncodeToString(leaf.Raw),
+		base64.StdEncoding.EncodeToString(intermediate.Raw),
+	}
+	sha1Sum := sha1.Sum(leaf.Raw)
+	sha256Sum := sha256.Sum256(leaf.Raw)
+	x5t := base64.RawURLEncoding.EncodeToString(sha1Sum[:])
+	x5tS256 := base64.RawURLEncoding.EncodeToString(sha256Sum[:])
+
+	chain := &jwt.CertificateChain{Roots: roots, Now: func() time.Time { return now }}
+	keys, err := chain.VerificationKeys(map[string]any{"alg": "ES256", "x5c": x5c, "x5t": x5t, "x5t#S256": x5tS256})
+	if err != nil {
+		t.Fatalf("VerificationKeys returned error: %v", err)
+	}
+	block, _ := pem.Decode(keys[0])
+	if len(keys) != 1 || block == nil || string(block.Bytes) != string(leaf.Raw) {
+		t.Errorf("Expected the leaf certificate as PEM, got %q", keys)
+	}
+
+	tests := []struct {
+		name   string
+		chain  *jwt.CertificateChain
+		header map[string]any
+	}{
+		{"No x5c", chain, map[string]any{"alg": "ES256"}},
+		{"Empty x5c", chain, map[string]any{"alg": "ES256", "x5c": []any{}}},
+		{"x5c not an array", chain, map[string]any{"alg": "ES256", "x5c": x5c[0]}},
+		{"x5c base64url", chain, map[string]any{"alg": "ES256", "x5c": []any{"-_-_"}}},
+		{"x5c not a certificate", chain, map[string]any{"alg": "ES256", "x5c": []any{"AAAA"}}},
+		{"Missing intermediate", chain, map[string]any{"alg": "ES256", "x5c": x5c[:1]}},
+		{"Untrusted root", &jwt.CertificateChain{Roots: x509.NewCertPool(), Now: chain.Now}, map[string]any{"alg": "ES256", "x5c": x5c}},
+		{"Expired", &jwt.CertificateChain{Roots: roots, Now: func() time.Time { return now.Add(48 * time.Hour) }}, map[string]any{"alg": "ES256", "x5c": x5c}},
+		{"No roots", &jwt.CertificateChain{}, map[string]any{"alg": "ES256", "x5c": x5c}},
+		{"HMAC algorithm", chain, map[string]any{"alg": "HS256", "x5c": x5c}},
+		{"RSA algorithm for an EC leaf", chain, map[string]any{"alg": "RS256", "x5c": x5c}},
+		{"x5t mismatch", chain, map[string]any{"alg": "ES256", "x5c": x5c, "x5t": x5tS256}},
+		{"x5t#S256 mismatch", chain, map[string]any{"alg": "ES256", "x5c": x5c, "x5t#S256": x5t}},
+		{"x5t not base64url", chain, map[string]any{"alg": "ES256", "x5c": x5c, "x5t": "+/=="}},
+		{"Leaf of other root", chain, map[string]any{"alg": "ES256", "x5c": []any{base64.StdEncoding.EncodeToString(otherRoot.Raw)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.chain.VerificationKeys(tt.header); !errors.Is(err, jwt.ErrCertificateChain) {
+				t.Errorf("Expected ErrCertificateChain, got %v", err)
+			}
+		})
+	}
+}
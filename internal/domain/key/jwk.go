@@ -0,0 +1,441 @@
+package key
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrAlgorithmMismatch is returned when a key cannot verify tokens signed
+// with an algorithm
+var ErrAlgorithmMismatch = errors.New("key does not match the algorithm")
+
+// JWK is a JSON Web Key as defined by RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA parameters
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	D  string `json:"d,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// EC and OKP parameters (D is shared with RSA)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	// Symmetric key value
+	K string `json:"k,omitempty"`
+}
+
+// NewJWK converts a public key, private key or []byte secret into a JWK
+func NewJWK(k any) (*JWK, error) {
+	switch k := k.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			N:   encodeInt(k.N),
+			E:   encodeInt(big.NewInt(int64(k.E))),
+		}, nil
+	case *rsa.PrivateKey:
+		jwk, _ := NewJWK(&k.PublicKey)
+		k.Precompute()
+		jwk.D = encodeInt(k.D)
+		if len(k.Primes) == 2 {
+			jwk.P = encodeInt(k.Primes[0])
+			jwk.Q = encodeInt(k.Primes[1])
+			jwk.DP = encodeInt(k.Precomputed.Dp)
+			jwk.DQ = encodeInt(k.Precomputed.Dq)
+			jwk.QI = encodeInt(k.Precomputed.Qinv)
+		}
+		return jwk, nil
+	case *ecdsa.PublicKey:
+		crv, size, err := curveName(k)
+		if err != nil {
+			return nil, err
+		}
+		x, y, err := ecCoordinates(k)
+		if err != nil {
+			return nil, err
+		}
+		return &JWK{
+			Kty: "EC",
+			Crv: crv,
+			X:   encodeFixed(x, size),
+			Y:   encodeFixed(y, size),
+		}, nil
+	case *ecdsa.PrivateKey:
+		jwk, err := NewJWK(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		ecdhKey, err := k.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode EC private key: %w", err)
+		}
+		jwk.D = base64.RawURLEncoding.EncodeToString(ecdhKey.Bytes())
+		return jwk, nil
+	case ed25519.PublicKey:
+		return &JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k),
+		}, nil
+	case ed25519.PrivateKey:
+		jwk, _ := NewJWK(k.Public())
+		jwk.D = base64.RawURLEncoding.EncodeToString(k.Seed())
+		return jwk, nil
+	case []byte:
+		if len(k) == 0 {
+			return nil, fmt.Errorf("empty symmetric key")
+		}
+		return &JWK{
+			Kty: "oct",
+			K:   base64.RawURLEncoding.EncodeToString(k),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", k)
+	}
+}
+
+// Public returns a copy of the JWK without private key parameters
+func (j *JWK) Public() *JWK {
+	public := *j
+	public.D, public.P, public.Q, public.DP, public.DQ, public.QI = "", "", "", "", "", ""
+	if public.Kty == "oct" {
+		public.K = ""
+	}
+	return &public
+}
+
+// IsPrivate reports whether the JWK carries private key material
+func (j *JWK) IsPrivate() bool {
+	return j.D != "" || j.K != ""
+}
+
+// Thumbprint computes the RFC 7638 JWK thumbprint (SHA-256, base64url)
+func (j *JWK) Thumbprint() (string, error) {
+	// Required members only, in lexicographic order, without whitespace
+	var members any
+	switch j.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{j.Crv, j.Kty, j.X, j.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	case "oct":
+		members = struct {
+			K   string `json:"k"`
+			Kty string `json:"kty"`
+		}{j.K, j.Kty}
+	default:
+		return "", fmt.Errorf("unsupported key type: %s", j.Kty)
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode thumbprint input: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// Thumbprint computes the RFC 7638 thumbprint of a key, suitable as a kid
+func Thumbprint(k crypto.PublicKey) (string, error) {
+	jwk, err := NewJWK(k)
+	if err != nil {
+		return "", err
+	}
+	return jwk.Thumbprint()
+}
+
+// encodeInt encodes a big integer as unsigned big-endian base64url
+func encodeInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+// encodeFixed encodes a coordinate padded to the curve's byte length
+func encodeFixed(b []byte, size int) string {
+	if len(b) < size {
+		padded := make([]byte, size)
+		copy(padded[size-len(b):], b)
+		b = padded
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// curveName returns the JWK curve name and coordinate size in bytes
+func curveName(k *ecdsa.PublicKey) (string, int, error) {
+	switch k.Curve.Params().Name {
+	case "P-256":
+		return "P-256", 32, nil
+	case "P-384":
+		return "P-384", 48, nil
+	case "P-521":
+		return "P-521", 66, nil
+	default:
+		return "", 0, fmt.Errorf("unsupported EC curve: %s", k.Curve.Params().Name)
+	}
+}
+
+// ecCoordinates extracts the affine X and Y coordinates of an EC public key
+func ecCoordinates(k *ecdsa.PublicKey) ([]byte, []byte, error) {
+	ecdhKey, err := k.ECDH()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode EC public key: %w", err)
+	}
+	point := ecdhKey.Bytes()
+	// Uncompressed point: 0x04 || X || Y
+	size := (len(point) - 1) / 2
+	return point[1 : 1+size], point[1+size:], nil
+}
+
+// CheckAlgorithm reports whether the JWK may verify tokens signed with alg.
+// The key type must belong to the algorithm's family: oct for HS, RSA for RS
+// and PS, EC on the matching curve for ES and OKP for EdDSA. The JWK's alg
+// member, when present, must equal alg. This keeps a published public key
+// from being used as an HMAC secret.
+func (j *JWK) CheckAlgorithm(alg string) error {
+	if j.Alg != "" && j.Alg != alg {
+		return fmt.Errorf("%w: the key is for %s, not %s", ErrAlgorithmMismatch, j.Alg, alg)
+	}
+	var ok bool
+	switch {
+	case strings.HasPrefix(alg, "HS"):
+		ok = j.Kty == "oct"
+	case strings.HasPrefix(alg, "RS"), strings.HasPrefix(alg, "PS"):
+		ok = j.Kty == "RSA"
+	case alg == "ES256":
+		ok = j.Kty == "EC" && j.Crv == "P-256"
+	case alg == "ES384":
+		ok = j.Kty == "EC" && j.Crv == "P-384"
+	case alg == "ES512":
+		ok = j.Kty == "EC" && j.Crv == "P-521"
+	case alg == "EdDSA":
+		ok = j.Kty == "OKP"
+	}
+	if !ok {
+		kind := j.Kty
+		if j.Crv != "" {
+			kind += " " + j.Crv
+		}
+		return fmt.Errorf("%w: %s keys cannot verify %q", ErrAlgorithmMismatch, kind, alg)
+	}
+	return nil
+}
+
+// ParseJWK parses a single JSON Web Key
+func ParseJWK(data []byte) (*JWK, error) {
+	var jwk JWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, fmt.Errorf("invalid JWK: %w", err)
+	}
+	if jwk.Kty == "" {
+		return nil, fmt.Errorf("invalid JWK: missing kty")
+	}
+	return &jwk, nil
+}
+
+// Key converts the JWK into a Go key. A private key (*rsa.PrivateKey,
+// *ecdsa.PrivateKey, ed25519.PrivateKey) is returned when the JWK carries
+// private parameters, a public key otherwise, and []byte for oct keys.
+func (j *JWK) Key() (any, error) {
+	switch j.Kty {
+	case "RSA":
+		return j.rsaKey()
+	case "EC":
+		return j.ecKey()
+	case "OKP":
+		return j.okpKey()
+	case "oct":
+		k, err := decodeParam("k", j.K)
+		if err != nil {
+			return nil, err
+		}
+		if len(k) == 0 {
+			return nil, fmt.Errorf("invalid JWK: missing k")
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", j.Kty)
+	}
+}
+
+// PublicKey converts the JWK into a Go public key
+func (j *JWK) PublicKey() (crypto.PublicKey, error) {
+	k, err := j.Key()
+	if err != nil {
+		return nil, err
+	}
+	return Public(k)
+}
+
+// rsaKey builds an RSA public or private key from the JWK parameters
+func (j *JWK) rsaKey() (any, error) {
+	n, err := decodeParam("n", j.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeParam("e", j.E)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("invalid JWK: bad RSA modulus or exponent")
+	}
+	publicKey := rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}
+	if j.D == "" {
+		return &publicKey, nil
+	}
+
+	var params [3][]byte
+	for i, p := range []struct{ name, value string }{{"d", j.D}, {"p", j.P}, {"q", j.Q}} {
+		if params[i], err = decodeParam(p.name, p.value); err != nil {
+			return nil, err
+		}
+		if len(params[i]) == 0 {
+			return nil, fmt.Errorf("invalid JWK: RSA private key requires %s", p.name)
+		}
+	}
+	privateKey := &rsa.PrivateKey{
+		PublicKey: publicKey,
+		D:         new(big.Int).SetBytes(params[0]),
+		Primes:    []*big.Int{new(big.Int).SetBytes(params[1]), new(big.Int).SetBytes(params[2])},
+	}
+	if err := privateKey.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid JWK: %w", err)
+	}
+	privateKey.Precompute()
+	return privateKey, nil
+}
+
+// ecKey builds an EC public or private key from the JWK parameters
+func (j *JWK) ecKey() (any, error) {
+	var curve elliptic.Curve
+	var ecdhCurve ecdh.Curve
+	switch j.Crv {
+	case "P-256":
+		curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported EC curve: %s", j.Crv)
+	}
+
+	x, err := decodeParam("x", j.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeParam("y", j.Y)
+	if err != nil {
+		return nil, err
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("invalid JWK: EC coordinates must be %d bytes", size)
+	}
+
+	// Let crypto/ecdh check the point is on the curve
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid JWK: %w", err)
+	}
+	publicKey := ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if j.D == "" {
+		return &publicKey, nil
+	}
+
+	d, err := decodeParam("d", j.D)
+	if err != nil {
+		return nil, err
+	}
+	ecdhKey, err := ecdhCurve.NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK: %w", err)
+	}
+	if !bytes.Equal(ecdhKey.PublicKey().Bytes(), point) {
+		return nil, fmt.Errorf("invalid JWK: EC private key does not match public key")
+	}
+	return &ecdsa.PrivateKey{PublicKey: publicKey, D: new(big.Int).SetBytes(d)}, nil
+}
+
+// okpKey builds an Ed25519 public or private key from the JWK parameters
+func (j *JWK) okpKey() (any, error) {
+	if j.Crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported OKP curve: %s", j.Crv)
+	}
+	x, err := decodeParam("x", j.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid JWK: Ed25519 public key must be %d bytes", ed25519.PublicKeySize)
+	}
+	if j.D == "" {
+		return ed25519.PublicKey(x), nil
+	}
+
+	seed, err := decodeParam("d", j.D)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid JWK: Ed25519 private key must be %d bytes", ed25519.SeedSize)
+	}
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	if !bytes.Equal(privateKey.Public().(ed25519.PublicKey), x) {
+		return nil, fmt.Errorf("invalid JWK: Ed25519 private key does not match public key")
+	}
+	return privateKey, nil
+}
+
+// decodeParam decodes a base64url JWK parameter
+func decodeParam(name, value string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK: %s is not valid base64url", name)
+	}
+	return b, nil
+}
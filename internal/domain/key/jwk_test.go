@@ -0,0 +1,173 @@
+package key_test
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"jwt/internal/domain/key"
+	"jwt/internal/interface/hash"
+)
+
+func TestJWK_RoundTrip(t *testing.T) {
+	for _, keyType := range []key.Type{key.RSA, key.EC, key.Ed25519, key.Oct} {
+		t.Run(string(keyType), func(t *testing.T) {
+			privateKey, err := key.Generate(keyType, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			jwk, err := key.NewJWK(privateKey)
+			if err != nil {
+				t.Fatalf("NewJWK returned error: %v", err)
+			}
+			data, _ := json.Marshal(jwk)
+
+			parsed, err := key.ParseJWK(data)
+			if err != nil {
+				t.Fatalf("ParseJWK returned error: %v", err)
+			}
+			k, err := parsed.Key()
+			if err != nil {
+				t.Fatalf("Key returned error: %v", err)
+			}
+
+			if secret, ok := privateKey.([]byte); ok {
+				if string(k.([]byte)) != string(secret) {
+					t.Error("Parsed secret does not match generated secret")
+				}
+				return
+			}
+			if !k.(interface{ Equal(crypto.PrivateKey) bool }).Equal(privateKey) {
+				t.Error("Parsed private key does not match generated key")
+			}
+
+			publicKey, err := parsed.Public().PublicKey()
+			if err != nil {
+				t.Fatalf("PublicKey returned error: %v", err)
+			}
+			want, _ := key.Public(privateKey)
+			if !publicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(want) {
+				t.Error("Parsed public key does not match generated key")
+			}
+		})
+	}
+}
+
+func TestParse_Formats(t *testing.T) {
+	privateKey, _ := key.Generate(key.RSA, 2048)
+	publicKey, _ := key.Public(privateKey)
+	publicPEM, _ := key.MarshalPublicKeyPEM(publicKey)
+	jwk, _ := key.NewJWK(publicKey)
+	jwk.Kid = "2024-01"
+	jwkJSON, _ := json.Marshal(jwk)
+	jwksJSON, _ := json.Marshal(key.JWKS{Keys: []key.JWK{{Kty: "oct", Kid: "other", K: "c2VjcmV0"}, *jwk}})
+
+	tests := []struct {
+		name   string
+		data   []byte
+		kid    string
+		format key.Format
+	}{
+		{"PEM", publicPEM, "", key.PEM},
+		{"JWK", jwkJSON, "", key.JWKFormat},
+		{"JWKS", jwksJSON, "2024-01", key.JWKSFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := key.DetectFormat(tt.data)
+			if err != nil || format != tt.format {
+				t.Errorf("Expected format %s, got %s (%v)", tt.format, format, err)
+			}
+			k, err := key.Parse(tt.data, tt.kid)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if !k.(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKey) {
+				t.Error("Parsed key does not match")
+			}
+		})
+	}
+
+	if _, err := key.Parse(jwksJSON, "missing"); !errors.Is(err, key.ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+	if _, err := key.Parse(jwksJSON, ""); err == nil {
+		t.Error("Expected error selecting from a multi-key JWKS without kid")
+	}
+	if _, err := key.Parse([]byte("not a key"), ""); err == nil {
+		t.Error("Expected error for unrecognized input")
+	}
+	if _, err := key.ParsePrivateKey(publicPEM); err == nil {
+		t.Error("Expected error parsing a public key as private")
+	}
+
+	// The RSA hasher accepts JWK material as well as PEM
+	privatePEM, _ := key.MarshalPrivateKeyPEM(privateKey)
+	hasher := &hash.RS256Hasher{}
+	data := []byte("header.payload")
+	signature, err := hasher.Sign(data, privatePEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hasher.Verify(data, signature, jwkJSON); err != nil {
+		t.Errorf("RS256Hasher could not verify with a JWK public key: %v", err)
+	}
+}
+
+func TestJWK_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		jwk  string
+	}{
+		{"Missing kty", `{"n":"AQAB"}`},
+		{"Unknown kty", `{"kty":"DSA"}`},
+		{"Bad base64", `{"kty":"RSA","n":"***","e":"AQAB"}`},
+		{"EC point off curve", `{"kty":"EC","crv":"P-256","x":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA","y":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE"}`},
+		{"Unknown curve", `{"kty":"EC","crv":"secp256k1","x":"AA","y":"AA"}`},
+		{"Short Ed25519 key", `{"kty":"OKP","crv":"Ed25519","x":"AAAA"}`},
+		{"RSA private without primes", `{"kty":"RSA","n":"AQAB","e":"AQAB","d":"AQAB"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwk, err := key.ParseJWK([]byte(tt.jwk))
+			if err == nil {
+				_, err = jwk.Key()
+			}
+			if err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
+
+func TestJWK_CheckAlgorithm(t *testing.T) {
+	tests := []struct {
+		jwk     key.JWK
+		alg     string
+		wantErr bool
+	}{
+		{key.JWK{Kty: "oct"}, "HS256", false},
+		{key.JWK{Kty: "RSA"}, "RS256", false},
+		{key.JWK{Kty: "RSA"}, "PS256", false},
+		{key.JWK{Kty: "EC", Crv: "P-256"}, "ES256", false},
+		{key.JWK{Kty: "EC", Crv: "P-384"}, "ES384", false},
+		{key.JWK{Kty: "OKP", Crv: "Ed25519"}, "EdDSA", false},
+		{key.JWK{Kty: "RSA", Alg: "RS256"}, "RS256", false},
+		{key.JWK{Kty: "RSA"}, "HS256", true},
+		{key.JWK{Kty: "EC", Crv: "P-256"}, "HS256", true},
+		{key.JWK{Kty: "oct"}, "RS256", true},
+		{key.JWK{Kty: "EC", Crv: "P-384"}, "ES256", true},
+		{key.JWK{Kty: "RSA", Alg: "PS256"}, "RS256", true},
+		{key.JWK{Kty: "RSA"}, "none", true},
+		{key.JWK{Kty: "RSA"}, "", true},
+	}
+	for _, tt := range tests {
+		err := tt.jwk.CheckAlgorithm(tt.alg)
+		if tt.wantErr != (err != nil) || (err != nil && !errors.Is(err, key.ErrAlgorithmMismatch)) {
+			t.Errorf("CheckAlgorithm(%+v, %q) = %v, want error %v", tt.jwk, tt.alg, err, tt.wantErr)
+		}
+	}
+}
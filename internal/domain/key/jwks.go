@@ -0,0 +1,44 @@
+package key
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrKeyNotFound is returned when a JWKS has no key with the requested kid
+var ErrKeyNotFound = errors.New("key not found")
+
+// JWKS is a JSON Web Key Set as defined by RFC 7517 section 5
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ParseJWKS parses a JSON Web Key Set
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+	if set.Keys == nil {
+		return nil, fmt.Errorf("invalid JWKS: missing keys")
+	}
+	return &set, nil
+}
+
+// Lookup returns the key with the given kid. An empty kid selects the only
+// key of a single-key set.
+func (s *JWKS) Lookup(kid string) (*JWK, error) {
+	if kid == "" {
+		if len(s.Keys) == 1 {
+			return &s.Keys[0], nil
+		}
+		return nil, fmt.Errorf("JWKS contains %d keys, a kid is required", len(s.Keys))
+	}
+	for i := range s.Keys {
+		if s.Keys[i].Kid == kid {
+			return &s.Keys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+}
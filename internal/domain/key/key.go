@@ -0,0 +1,150 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+)
+
+// Type represents the supported key types
+type Type string
+
+// Type constants represent the supported key types
+const (
+	// RSA represents an RSA key pair
+	RSA Type = "rsa"
+	// EC represents an elliptic curve (P-256, P-384, P-521) key pair
+	EC Type = "ec"
+	// Ed25519 represents an Ed25519 key pair
+	Ed25519 Type = "ed25519"
+	// Oct represents a symmetric (HMAC) secret
+	Oct Type = "oct"
+)
+
+// DefaultSize returns the default key size in bits for the key type
+func DefaultSize(keyType Type) int {
+	switch keyType {
+	case RSA:
+		return 2048
+	case EC, Oct:
+		return 256
+	default:
+		return 0
+	}
+}
+
+// DefaultAlgorithm returns the JWS algorithm conventionally used with a key
+func DefaultAlgorithm(keyType Type, size int) string {
+	switch keyType {
+	case RSA:
+		return "RS256"
+	case EC:
+		switch size {
+		case 384:
+			return "ES384"
+		case 521:
+			return "ES512"
+		default:
+			return "ES256"
+		}
+	case Ed25519:
+		return "EdDSA"
+	case Oct:
+		switch {
+		case size >= 512:
+			return "HS512"
+		case size >= 384:
+			return "HS384"
+		default:
+			return "HS256"
+		}
+	default:
+		return ""
+	}
+}
+
+// Generate creates a new private key of the given type. Size is the RSA
+// modulus, the EC curve size or the secret length in bits; zero selects the
+// default. Symmetric secrets are returned as []byte holding printable
+// base64url text so they can be passed through JWT_SECRET_KEY unchanged.
+func Generate(keyType Type, size int) (crypto.PrivateKey, error) {
+	if size == 0 {
+		size = DefaultSize(keyType)
+	}
+
+	switch keyType {
+	case RSA:
+		if size < 2048 {
+			return nil, fmt.Errorf("RSA keys must be at least 2048 bits, got %d", size)
+		}
+		return rsa.GenerateKey(rand.Reader, size)
+	case EC:
+		curve, err := curveForSize(size)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	case Ed25519:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	case Oct:
+		if size < 256 || size%8 != 0 {
+			return nil, fmt.Errorf("secrets must be a multiple of 8 bits and at least 256 bits, got %d", size)
+		}
+		random := make([]byte, size/8)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		return []byte(base64.RawURLEncoding.EncodeToString(random)), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", keyType)
+	}
+}
+
+// Public returns the public half of a private key. Public keys and symmetric
+// secrets (which have no public half) are returned unchanged.
+func Public(privateKey crypto.PrivateKey) (crypto.PublicKey, error) {
+	switch k := privateKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return &k.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey, nil
+	case ed25519.PrivateKey:
+		return k.Public(), nil
+	case []byte:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", privateKey)
+	}
+}
+
+// isPrivate reports whether k is private (or symmetric) key material
+func isPrivate(k any) bool {
+	switch k.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey, []byte:
+		return true
+	default:
+		return false
+	}
+}
+
+// curveForSize maps a curve size in bits to the NIST curve
+func curveForSize(size int) (elliptic.Curve, error) {
+	switch size {
+	case 256:
+		return elliptic.P256(), nil
+	case 384:
+		return elliptic.P384(), nil
+	case 521:
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported EC curve size: %d (use 256, 384 or 521)", size)
+	}
+}
@@ -0,0 +1,158 @@
+package key_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+
+	"jwt/internal/domain/key"
+	"jwt/internal/interface/hash"
+)
+
+func TestGenerate_PEMRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType key.Type
+		size    int
+	}{
+		{"RSA", key.RSA, 2048},
+		{"EC P-256", key.EC, 256},
+		{"EC P-521", key.EC, 521},
+		{"Ed25519", key.Ed25519, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKey, err := key.Generate(tt.keyType, tt.size)
+			if err != nil {
+				t.Fatalf("Generate returned error: %v", err)
+			}
+			publicKey, err := key.Public(privateKey)
+			if err != nil {
+				t.Fatalf("Public returned error: %v", err)
+			}
+
+			privatePEM, err := key.MarshalPrivateKeyPEM(privateKey)
+			if err != nil {
+				t.Fatalf("MarshalPrivateKeyPEM returned error: %v", err)
+			}
+			publicPEM, err := key.MarshalPublicKeyPEM(publicKey)
+			if err != nil {
+				t.Fatalf("MarshalPublicKeyPEM returned error: %v", err)
+			}
+
+			parsedPrivate, err := key.ParsePrivateKeyPEM(privatePEM)
+			if err != nil {
+				t.Fatalf("ParsePrivateKeyPEM returned error: %v", err)
+			}
+			parsedPublic, err := key.ParsePublicKeyPEM(publicPEM)
+			if err != nil {
+				t.Fatalf("ParsePublicKeyPEM returned error: %v", err)
+			}
+
+			if !parsedPrivate.(interface{ Equal(crypto.PrivateKey) bool }).Equal(privateKey) {
+				t.Error("Parsed private key does not match generated key")
+			}
+			if !parsedPublic.(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKey) {
+				t.Error("Parsed public key does not match generated key")
+			}
+
+			// The kid must be stable across the private and public forms
+			privateJWK, err := key.NewJWK(privateKey)
+			if err != nil {
+				t.Fatalf("NewJWK returned error: %v", err)
+			}
+			privateKid, _ := privateJWK.Thumbprint()
+			publicKid, err := key.Thumbprint(publicKey)
+			if err != nil {
+				t.Fatalf("Thumbprint returned error: %v", err)
+			}
+			if privateKid != publicKid {
+				t.Errorf("Expected kid %s from private key, got %s", publicKid, privateKid)
+			}
+			if privateJWK.Public().IsPrivate() {
+				t.Error("Public JWK still carries private parameters")
+			}
+		})
+	}
+}
+
+func TestGenerate_KeyTypes(t *testing.T) {
+	rsaKey, _ := key.Generate(key.RSA, 0)
+	if k, ok := rsaKey.(*rsa.PrivateKey); !ok || k.N.BitLen() != 2048 {
+		t.Errorf("Expected 2048-bit RSA key by default, got %T", rsaKey)
+	}
+	ecKey, _ := key.Generate(key.EC, 384)
+	if k, ok := ecKey.(*ecdsa.PrivateKey); !ok || k.Curve.Params().Name != "P-384" {
+		t.Errorf("Expected P-384 key, got %T", ecKey)
+	}
+	edKey, _ := key.Generate(key.Ed25519, 0)
+	if _, ok := edKey.(ed25519.PrivateKey); !ok {
+		t.Errorf("Expected Ed25519 key, got %T", edKey)
+	}
+	secret, _ := key.Generate(key.Oct, 256)
+	if s, ok := secret.([]byte); !ok || len(s) < 32 {
+		t.Errorf("Expected at least 32 byte secret, got %T", secret)
+	}
+
+	if _, err := key.Generate(key.RSA, 1024); err == nil {
+		t.Error("Expected error for 1024-bit RSA key")
+	}
+	if _, err := key.Generate(key.EC, 255); err == nil {
+		t.Error("Expected error for unsupported curve size")
+	}
+	if _, err := key.Generate("dsa", 0); err == nil {
+		t.Error("Expected error for unsupported key type")
+	}
+}
+
+func TestGenerate_LoadableByHashers(t *testing.T) {
+	privateKey, err := key.Generate(key.RSA, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, _ := key.Public(privateKey)
+	privatePEM, _ := key.MarshalPrivateKeyPEM(privateKey)
+	publicPEM, _ := key.MarshalPublicKeyPEM(publicKey)
+
+	hasher := &hash.RS256Hasher{}
+	data := []byte("header.payload")
+	signature, err := hasher.Sign(data, privatePEM)
+	if err != nil {
+		t.Fatalf("RS256Hasher could not sign with a generated PKCS#8 key: %v", err)
+	}
+	if err := hasher.Verify(data, signature, publicPEM); err != nil {
+		t.Errorf("RS256Hasher could not verify with a generated PKIX key: %v", err)
+	}
+
+	secret, _ := key.Generate(key.Oct, 256)
+	hmac := &hash.HS256Hasher{}
+	mac, err := hmac.Sign(data, secret.([]byte))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hmac.Verify(data, mac, secret.([]byte)); err != nil {
+		t.Errorf("HS256Hasher could not use a generated secret: %v", err)
+	}
+}
+
+func TestThumbprint_RFC7638(t *testing.T) {
+	// Example from RFC 7638 section 3.1
+	jwk := &key.JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Alg: "RS256",
+		Kid: "2011-04-29",
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatalf("Thumbprint returned error: %v", err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; thumbprint != want {
+		t.Errorf("Expected thumbprint %s, got %s", want, thumbprint)
+	}
+}
@@ -0,0 +1,142 @@
+package key
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+)
+
+// Format identifies an encoding of key material
+type Format string
+
+// Format constants represent the supported key encodings
+const (
+	// PEM is a PEM block holding a PKCS#1, PKCS#8, SEC 1, PKIX or X.509 structure
+	PEM Format = "pem"
+	// JWKFormat is a single JSON Web Key
+	JWKFormat Format = "jwk"
+	// JWKSFormat is a JSON Web Key Set
+	JWKSFormat Format = "jwks"
+)
+
+// DetectFormat inspects key material and reports how it is encoded
+func DetectFormat(data []byte) (Format, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.Contains(trimmed, []byte("-----BEGIN ")):
+		return PEM, nil
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var probe struct {
+			Keys json.RawMessage `json:"keys"`
+		}
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return "", fmt.Errorf("invalid key JSON: %w", err)
+		}
+		if probe.Keys != nil {
+			return JWKSFormat, nil
+		}
+		return JWKFormat, nil
+	default:
+		return "", fmt.Errorf("unrecognized key format: expected PEM, JWK or JWKS")
+	}
+}
+
+// ParseJWKFromAny returns key material in any supported format as a JWK. For
+// a JWKS the key is selected by kid.
+func ParseJWKFromAny(data []byte, kid string) (*JWK, error) {
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case JWKFormat:
+		return ParseJWK(data)
+	case JWKSFormat:
+		set, err := ParseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+		return set.Lookup(kid)
+	default:
+		k, err := ParsePrivateKeyPEM(data)
+		if err != nil {
+			if k, err = ParsePublicKeyPEM(data); err != nil {
+				return nil, err
+			}
+		}
+		return NewJWK(k)
+	}
+}
+
+// Parse decodes key material in any supported format: PEM (private key,
+// public key or certificate), a JWK, or a JWKS (selected by kid). Private
+// keys are returned when present, public keys otherwise.
+func Parse(data []byte, kid string) (any, error) {
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	if format == PEM {
+		if privateKey, err := ParsePrivateKeyPEM(data); err == nil {
+			return privateKey, nil
+		}
+		return ParsePublicKeyPEM(data)
+	}
+
+	jwk, err := ParseJWKFromAny(data, kid)
+	if err != nil {
+		return nil, err
+	}
+	return jwk.Key()
+}
+
+// ParsePrivateKey parses a private key from PEM or JWK material
+func ParsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	k, err := Parse(data, "")
+	if err != nil {
+		return nil, err
+	}
+	if !isPrivate(k) {
+		return nil, fmt.Errorf("expected a private key, got a public key")
+	}
+	return k, nil
+}
+
+// ParsePublicKey parses a public key from PEM or JWK material. When given a
+// private key its public half is returned.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	k, err := Parse(data, "")
+	if err != nil {
+		return nil, err
+	}
+	return Public(k)
+}
+
+// ParseRSAPrivateKey parses an RSA private key from PEM or JWK material
+func ParseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	privateKey, err := ParsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an RSA private key, got %s", describe(privateKey))
+	}
+	return rsaKey, nil
+}
+
+// ParseRSAPublicKey parses an RSA public key from PEM or JWK material
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	publicKey, err := ParsePublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an RSA public key, got %s", describe(publicKey))
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,94 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPEM is returned when the input does not contain a PEM block
+var ErrInvalidPEM = errors.New("invalid PEM: no PEM block found")
+
+// MarshalPrivateKeyPEM encodes a private key as a PKCS#8 "PRIVATE KEY" block
+func MarshalPrivateKeyPEM(privateKey crypto.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPublicKeyPEM encodes a public key as a PKIX "PUBLIC KEY" block
+func MarshalPublicKeyPEM(publicKey crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded private key. PKCS#8, PKCS#1 and
+// SEC 1 encodings are accepted regardless of the block's label, since keys
+// produced by other tools are frequently mislabelled.
+func ParsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	if privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	if privateKey, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key format in %q block", block.Type)
+}
+
+// ParsePublicKeyPEM parses a PEM encoded public key. PKIX and PKCS#1 public
+// keys, X.509 certificates and private keys (whose public half is returned)
+// are accepted.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	if publicKey, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return publicKey, nil
+	}
+	if publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return publicKey, nil
+	}
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		return cert.PublicKey, nil
+	}
+	if privateKey, err := ParsePrivateKeyPEM(data); err == nil {
+		return Public(privateKey)
+	}
+
+	return nil, fmt.Errorf("unsupported public key format in %q block", block.Type)
+}
+
+// describe names a key's type for error messages
+func describe(k any) string {
+	switch k.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return "an RSA key"
+	case *ecdsa.PrivateKey, *ecdsa.PublicKey:
+		return "an EC key"
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return "an Ed25519 key"
+	default:
+		return fmt.Sprintf("%T", k)
+	}
+}
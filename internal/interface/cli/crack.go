@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/usecase/crack"
+)
+
+// runCrack tries the secrets of a wordlist against an HMAC-signed token
+func (h *Handler) runCrack(args []string) error {
+	flags := flag.NewFlagSet("crack", flag.ContinueOnError)
+	wordlist := flags.String("wordlist", "", "File with one candidate secret per line")
+	workers := flags.Int("workers", 0, "Number of concurrent workers (default: number of CPUs)")
+	flags.Usage = func() {
+		fmt.Print(CrackUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *wordlist == "" || flags.NArg() != 1 {
+		fmt.Print(CrackUsageMessage)
+		return fmt.Errorf("a wordlist and a token are required")
+	}
+
+	file, err := os.Open(*wordlist)
+	if err != nil {
+		return fmt.Errorf("failed to open wordlist: %w", err)
+	}
+	defer file.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := crack.Run(ctx, flags.Arg(0), file, *workers)
+	if err != nil {
+		return err
+	}
+	if !result.Found {
+		fmt.Printf("No match: %d candidate(s) tried against %s\n", result.Tried, result.Algorithm)
+		return nil
+	}
+
+	fmt.Printf("Secret found after %d candidate(s): %q\n", result.Tried, result.Secret)
+	minSize := hash.MinKeySize(result.Algorithm)
+	if len(result.Secret) < minSize {
+		fmt.Printf("The secret is %d bytes; RFC 7518 requires at least %d for %s\n", len(result.Secret), minSize, result.Algorithm)
+	}
+	fmt.Println("Rotate this secret: anyone holding the wordlist can forge tokens.")
+	return nil
+}
+
+// CrackUsageMessage is the help text for the crack command
+const CrackUsageMessage = `Usage:
+  jwt crack -wordlist <file> [-workers N] <token>
+
+Audits an HS256/HS384/HS512 token for a guessable secret by trying every
+line of the wordlist as the HMAC key. Only run this against tokens you are
+authorized to test.
+
+Flags:
+  -wordlist string
+        File with one candidate secret per line
+  -workers int
+        Number of concurrent workers (default: number of CPUs)
+
+Example:
+  jwt crack -wordlist common-secrets.txt eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+`
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+// timeClaims are payload claims holding NumericDate values
+var timeClaims = map[string]bool{"exp": true, "nbf": true, "iat": true, "auth_time": true}
+
+// runDiff decodes two tokens and prints the differences of their headers
+// and payloads. With -validate, each token's validation result is shown too.
+func (h *Handler) runDiff(args []string, validate bool) error {
+	if len(args) != 2 {
+		fmt.Print(DiffUsageMessage)
+		return fmt.Errorf("two tokens are required")
+	}
+	parser, ok := h.decoder.(jwt.Parser)
+	if !ok {
+		return fmt.Errorf("the configured decoder cannot parse tokens")
+	}
+
+	var tokens [2]*jwt.Token
+	for i, raw := range args {
+		token, err := parser.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("failed to decode token %c: %w", 'A'+i, err)
+		}
+		tokens[i] = token
+	}
+
+	printChanges("Header", jwt.Diff(tokens[0].Header, tokens[1].Header), false)
+	printChanges("Payload", jwt.Diff(tokens[0].Claims, tokens[1].Claims), true)
+
+	if validate {
+		fmt.Println("Validation:")
+		for i, raw := range args {
+			status := "valid"
+			if _, err := h.decoder.Decode(raw, true); err != nil {
+				status = err.Error()
+			}
+			fmt.Printf("  %c: %s\n", 'A'+i, status)
+		}
+	}
+	return nil
+}
+
+// printChanges prints one section of the diff
+func printChanges(section string, changes []jwt.Change, payload bool) {
+	fmt.Printf("%s:\n", section)
+	if len(changes) == 0 {
+		fmt.Println("  (no differences)")
+	}
+	for _, c := range changes {
+		isTime := payload && timeClaims[c.Path]
+		switch c.Kind {
+		case jwt.Added:
+			fmt.Printf("  + %s: %s\n", c.Path, formatValue(c.New, isTime))
+		case jwt.Removed:
+			fmt.Printf("  - %s: %s\n", c.Path, formatValue(c.Old, isTime))
+		default:
+			line := fmt.Sprintf("  ~ %s: %s -> %s", c.Path, formatValue(c.Old, isTime), formatValue(c.New, isTime))
+			if isTime {
+				if from, ok := asTime(c.Old); ok {
+					if to, ok := asTime(c.New); ok {
+						line += fmt.Sprintf(" [%+v]", to.Sub(from))
+					}
+				}
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// formatValue renders a value as compact JSON, adding the UTC time for
+// NumericDate claims
+func formatValue(v any, isTime bool) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	out := string(data)
+	if isTime {
+		if t, ok := asTime(v); ok {
+			out += " (" + t.UTC().Format(time.RFC3339) + ")"
+		}
+	}
+	return out
+}
+
+// asTime interprets a claim value as a NumericDate
+func asTime(v any) (time.Time, bool) {
+	data, err := json.Marshal(v)
+	if err != nil || strings.HasPrefix(string(data), "\"") {
+		return time.Time{}, false
+	}
+	var date jwt.NumericDate
+	if err := json.Unmarshal(data, &date); err != nil || date.IsZero() {
+		return time.Time{}, false
+	}
+	return date.Time, true
+}
+
+// DiffUsageMessage is the help text for the diff command
+const DiffUsageMessage = `Usage:
+  jwt [-validate] diff <tokenA> <tokenB>
+
+Decodes both tokens and lists how B differs from A, path by path:
+
+  + path: value          only in B
+  - path: value          only in A
+  ~ path: old -> new     changed
+
+Nested objects and arrays are compared member by member (e.g.
+"metadata.location", "roles[1]"). Time claims (exp, nbf, iat, auth_time)
+are shown as UTC times with the difference between them. With -validate,
+the result of validating each token is printed as well.
+
+Example:
+  jwt -validate diff eyJhbGciOi...A eyJhbGciOi...B
+`
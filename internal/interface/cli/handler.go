@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"jwt/internal/config"
 	"jwt/internal/domain/hash"
 	"jwt/internal/domain/jwt"
 	jwtusecase "jwt/internal/usecase/jwt"
@@ -23,14 +24,55 @@ func Run(args ...string) error {
 
 // Handler handles CLI commands
 type Handler struct {
-	decoder jwt.Decoder
+	decoder  jwt.Decoder
+	settings config.Settings
+}
+
+// HandlerOption configures optional Handler behaviour
+type HandlerOption func(*Handler)
+
+// WithSettings sets the resolved settings used by commands that build their
+// own validation pipeline. Without it only the environment is consulted.
+func WithSettings(settings config.Settings) HandlerOption {
+	return func(h *Handler) {
+		h.settings = settings
+	}
+}
+
+// command runs a subcommand with the arguments following its name; validate
+// reports whether -validate preceded it
+type command func(h *Handler, args []string, validate bool) error
+
+// withArgs adapts a subcommand that ignores -validate
+func withArgs(run func(*Handler, []string) error) command {
+	return func(h *Handler, args []string, _ bool) error {
+		return run(h, args)
+	}
+}
+
+// commands maps each subcommand that parses its own arguments to its
+// handler. Every entry must be listed in UsageMessage.
+var commands = map[string]command{
+	"keys":              withArgs((*Handler).runKeys),
+	"serve":             withArgs((*Handler).runServe),
+	"introspect-server": withArgs((*Handler).runIntrospectServer),
+	"revoke":            withArgs((*Handler).runRevoke),
+	"diff":              (*Handler).runDiff,
+	"lint":              withArgs((*Handler).runLint),
+	"crack":             withArgs((*Handler).runCrack),
+	"sign":              withArgs((*Handler).runSign),
 }
 
 // NewHandler creates a new CLI handler
-func NewHandler(decoder jwt.Decoder) *Handler {
-	return &Handler{
-		decoder: decoder,
+func NewHandler(decoder jwt.Decoder, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		decoder:  decoder,
+		settings: config.Resolve(config.Profile{}, config.Settings{}, os.Getenv),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Run executes the CLI command
@@ -44,11 +86,17 @@ func (h *Handler) Run(args ...string) error {
 	command := ""
 	validate := false
 	algorithm := hash.HS256
+	var rest []string
 	for i, arg := range args {
 		if arg == "decode" {
 			command = arg
 			break
 		}
+		if _, ok := commands[arg]; ok {
+			command = arg
+			rest = args[i+1:]
+			break
+		}
 		if arg == "-validate" {
 			validate = true
 		}
@@ -75,18 +123,6 @@ func (h *Handler) Run(args ...string) error {
 			return fmt.Errorf("JWT token is required")
 		}
 
-		if validate {
-			if algorithm == hash.RS256 {
-				if os.Getenv("JWT_PUBLIC_KEY") == "" {
-					return fmt.Errorf("JWT_PUBLIC_KEY environment variable is required for RS256 validation")
-				}
-			} else {
-				if os.Getenv("JWT_SECRET_KEY") == "" {
-					return fmt.Errorf("JWT_SECRET_KEY environment variable is required for validation")
-				}
-			}
-		}
-
 		jwtData, err := h.decoder.Decode(token, validate)
 		if err != nil {
 			return fmt.Errorf("failed to decode JWT: %w", err)
@@ -105,8 +141,7 @@ func (h *Handler) Run(args ...string) error {
 		fmt.Println(token)
 		return nil
 	default:
-		fmt.Print(UsageMessage)
-		return nil
+		return commands[command](h, rest, validate)
 	}
 }
 
@@ -118,15 +153,54 @@ Usage:
 
 Commands:
   decode <token>    Decode a JWT token
+  diff <a> <b>     Show how two tokens' headers and claims differ
+  lint <token>     Audit a token for common security problems
+  crack <token>    Audit an HMAC token's secret against a wordlist
+  sign <claims>    Sign a JSON claims object (key file, secret or PKCS#11)
   generate         Generate a test JWT token (uses HS256 by default)
+  keys generate    Generate a key pair as PEM and/or JWK (see: jwt keys)
+  keys convert     Convert a key between PEM, JWK and JWKS
+  keys thumbprint  Print the RFC 7638 thumbprint of a key
+  serve            Run a local mock identity provider (see: jwt serve -h)
+  introspect-server
+                   Run an RFC 7662 token introspection endpoint
+  revoke           Add, remove or list denylist entries (see: jwt revoke)
 
 Flags:
   -algorithm string
-        Hash algorithm to use (HS256, HS384, HS512) (default "HS256")
+        Hash algorithm to use (HS256, HS384, HS512, RS256, PS256, ES256)
+        (default "HS256")
   -validate
-        Validate JWT signature
+        Validate JWT signature and claims (exp, nbf, iss, aud)
   -generate
         Generate a test JWT token with realistic claims
+  -profile string
+        Named profile from the config file
+  -config string
+        Path to the config file (default jwt/config.toml in the user config directory)
+  -issuer string
+        Expected token issuer (iss)
+  -audience string
+        Expected token audience (aud)
+  -leeway duration
+        Allowed clock skew when checking exp and nbf (e.g. 30s)
+  -jwks-url string
+        Fetch verification keys from a JWKS URL (selected by kid)
+  -denylist string
+        Reject tokens listed in this revocation file
+  -keyring string
+        Verify with the keys of a JSON keyring file (selected by kid)
+  -x5c-roots string
+        Verify with the token's x5c leaf certificate, whose chain must lead
+        to a root CA in this PEM bundle
+  -trusted-key-urls string
+        Fetch keys from the token's jku (JWKS) or x5u (PEM certificate) URL
+        when it is under one of these comma-separated prefixes
+  -strict
+        Reject duplicate JSON members, non-canonical base64url and tokens
+        above -max-token-size
+  -max-token-size int
+        Size limit in bytes for -strict (default 16384)
 
 Examples:
   # Decode a JWT token
@@ -144,6 +218,14 @@ Examples:
   # Use a different algorithm
   jwt -algorithm HS384 decode eyJhbGciOiJIUzM4NCIsInR5cCI6IkpXVCJ9...
 
+  # Validate using the "staging" profile from the config file
+  jwt -profile staging -validate decode eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9...
+
 Environment Variables:
-  JWT_SECRET_KEY    Secret key for validating JWT signatures
+  JWT_SECRET_KEY    Secret key for validating HMAC signatures
+  JWT_PUBLIC_KEY    PEM encoded public key for validating RSA signatures
+  JWT_CONFIG        Path to the config file
+
+Settings are merged in this order (highest precedence first):
+  flags, environment variables, the selected profile, built-in defaults
 `
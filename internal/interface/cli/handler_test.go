@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandsAreListedInUsage(t *testing.T) {
+	for name := range commands {
+		if !strings.Contains(UsageMessage, "\n  "+name+" ") && !strings.Contains(UsageMessage, "\n  "+name+"\n") {
+			t.Errorf("Command %q is missing from UsageMessage", name)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"jwt/internal/interface/server"
+)
+
+// runIntrospectServer starts an RFC 7662 token introspection endpoint
+func (h *Handler) runIntrospectServer(args []string) error {
+	flags := flag.NewFlagSet("introspect-server", flag.ContinueOnError)
+	addr := flags.String("addr", "127.0.0.1:8081", "Address to listen on")
+	clientID := flags.String("client-id", "", "Require HTTP Basic credentials with this client ID")
+	clientSecret := flags.String("client-secret", "", "Require HTTP Basic credentials with this secret")
+	flags.Usage = func() {
+		fmt.Print(IntrospectUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if h.settings.X5CRoots == "" && h.settings.KeyURLs == "" && h.settings.JWKSURL == "" && h.settings.Keyring == "" && len(h.settings.VerificationKey()) == 0 {
+		return fmt.Errorf("a verification key is required: set JWT_SECRET_KEY, JWT_PUBLIC_KEY, -jwks-url, -keyring, -x5c-roots, -trusted-key-urls or a profile")
+	}
+	verifier, err := NewVerifier(h.settings)
+	if err != nil {
+		return err
+	}
+
+	introspector := server.NewIntrospector(server.IntrospectorConfig{
+		Verifier:     verifier,
+		ClientID:     *clientID,
+		ClientSecret: *clientSecret,
+	})
+
+	fmt.Printf("Token introspection (%s) listening on %s\n", h.settings.Algorithm, *addr)
+	fmt.Printf("  POST http://%s/introspect\n", *addr)
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           introspector.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// IntrospectUsageMessage is the help text for the introspect-server command
+const IntrospectUsageMessage = `Usage:
+  jwt [global flags] introspect-server [flags]
+
+Starts an RFC 7662 token introspection endpoint for services that cannot
+link the Go code. Tokens are validated with the same key source and policy
+as "decode -validate": the global -algorithm, -profile, -jwks-url, -issuer,
+-audience and -leeway flags, and JWT_SECRET_KEY / JWT_PUBLIC_KEY.
+
+  POST /introspect   token=<jwt> (form) or {"token": "<jwt>"} (JSON)
+
+Valid tokens return {"active": true, ...claims}; anything else returns
+{"active": false}.
+
+Flags:
+  -addr string
+        Address to listen on (default "127.0.0.1:8081")
+  -client-id string
+        Require HTTP Basic credentials with this client ID
+  -client-secret string
+        Require HTTP Basic credentials with this secret
+
+Examples:
+  jwt -profile staging introspect-server -addr :8081
+  curl -s -d token=eyJhbGciOi... localhost:8081/introspect
+`
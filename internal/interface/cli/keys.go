@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/key"
+)
+
+// runKeys dispatches the keys subcommands
+func (h *Handler) runKeys(args []string) error {
+	if len(args) < 1 {
+		fmt.Print(KeysUsageMessage)
+		return nil
+	}
+
+	switch args[0] {
+	case "generate":
+		return h.runKeysGenerate(args[1:])
+	case "convert":
+		return h.runKeysConvert(args[1:])
+	case "thumbprint":
+		return h.runKeysThumbprint(args[1:])
+	default:
+		return fmt.Errorf("unknown keys command: %s", args[0])
+	}
+}
+
+// runKeysGenerate generates a key pair and writes it as PEM and/or JWK
+func (h *Handler) runKeysGenerate(args []string) error {
+	flags := flag.NewFlagSet("keys generate", flag.ContinueOnError)
+	keyType := flags.String("type", "rsa", "Key type (rsa, ec, oct)")
+	size := flags.Int("bits", 0, "Key size in bits (RSA modulus, EC curve 256 or secret length)")
+	format := flags.String("format", "pem", "Output format (pem, jwk, both)")
+	alg := flags.String("alg", "", "JWS algorithm recorded in the JWK (default depends on type)")
+	out := flags.String("out", "", "Write files with this path prefix instead of printing")
+	flags.Usage = func() {
+		fmt.Print(KeysUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var writePEM, writeJWK bool
+	switch strings.ToLower(*format) {
+	case "pem":
+		writePEM = true
+	case "jwk":
+		writeJWK = true
+	case "both":
+		writePEM, writeJWK = true, true
+	default:
+		return fmt.Errorf("unsupported format: %s", *format)
+	}
+
+	kt := key.Type(strings.ToLower(*keyType))
+	bits := *size
+	if bits == 0 {
+		bits = key.DefaultSize(kt)
+	}
+
+	privateKey, err := key.Generate(kt, bits)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	publicKey, err := key.Public(privateKey)
+	if err != nil {
+		return err
+	}
+
+	jwk, err := key.NewJWK(privateKey)
+	if err != nil {
+		return err
+	}
+	kid, err := jwk.Thumbprint()
+	if err != nil {
+		return err
+	}
+	algorithm := *alg
+	if algorithm == "" {
+		algorithm = key.DefaultAlgorithm(kt, bits)
+	}
+	if err := checkGeneratedKey(jwk, algorithm); err != nil {
+		return err
+	}
+	jwk.Kid = kid
+	jwk.Use = "sig"
+	jwk.Alg = algorithm
+
+	var files []keyFile
+	if writePEM {
+		if kt == key.Oct {
+			// Secrets have no PEM form; the raw value is what JWT_SECRET_KEY expects
+			files = append(files, keyFile{".key", append(privateKey.([]byte), '\n'), true})
+		} else {
+			privatePEM, err := key.MarshalPrivateKeyPEM(privateKey)
+			if err != nil {
+				return err
+			}
+			publicPEM, err := key.MarshalPublicKeyPEM(publicKey)
+			if err != nil {
+				return err
+			}
+			files = append(files, keyFile{".pem", privatePEM, true}, keyFile{".pub.pem", publicPEM, false})
+		}
+	}
+	if writeJWK {
+		privateJSON, err := json.MarshalIndent(jwk, "", "  ")
+		if err != nil {
+			return err
+		}
+		files = append(files, keyFile{".jwk", append(privateJSON, '\n'), true})
+		if kt != key.Oct {
+			publicJSON, err := json.MarshalIndent(jwk.Public(), "", "  ")
+			if err != nil {
+				return err
+			}
+			files = append(files, keyFile{".pub.jwk", append(publicJSON, '\n'), false})
+		}
+	}
+
+	if *out == "" {
+		for _, f := range files {
+			fmt.Print(string(f.data))
+		}
+		fmt.Fprintf(os.Stderr, "Key ID: %s\n", kid)
+		return nil
+	}
+
+	for _, f := range files {
+		perm := os.FileMode(0o644)
+		if f.private {
+			perm = 0o600
+		}
+		path := *out + f.suffix
+		if err := os.WriteFile(path, f.data, perm); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	fmt.Printf("Key ID: %s\n", kid)
+	return nil
+}
+
+// runKeysConvert converts a key between PEM, JWK and JWKS
+func (h *Handler) runKeysConvert(args []string) error {
+	flags := flag.NewFlagSet("keys convert", flag.ContinueOnError)
+	to := flags.String("to", "jwk", "Output format (pem, jwk, jwks)")
+	kid := flags.String("kid", "", "Key to select when the input is a JWKS")
+	public := flags.Bool("public", false, "Output only the public key")
+	alg := flags.String("alg", "", "JWS algorithm recorded in JWK output")
+	flags.Usage = func() {
+		fmt.Print(KeysUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := readKeyInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	jwk, err := key.ParseJWKFromAny(data, *kid)
+	if err != nil {
+		return fmt.Errorf("failed to parse key: %w", err)
+	}
+	if jwk.Kid == "" {
+		if jwk.Kid, err = jwk.Thumbprint(); err != nil {
+			return err
+		}
+	}
+	if *alg != "" {
+		jwk.Alg = *alg
+	}
+
+	switch strings.ToLower(*to) {
+	case "pem":
+		k, err := jwk.Key()
+		if err != nil {
+			return fmt.Errorf("failed to parse key: %w", err)
+		}
+		if _, ok := k.([]byte); ok {
+			return fmt.Errorf("symmetric keys have no PEM form")
+		}
+		var out []byte
+		if *public || !jwk.IsPrivate() {
+			publicKey, err := key.Public(k)
+			if err != nil {
+				return err
+			}
+			out, err = key.MarshalPublicKeyPEM(publicKey)
+			if err != nil {
+				return err
+			}
+		} else if out, err = key.MarshalPrivateKeyPEM(k); err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		return nil
+	case "jwk":
+		if *public {
+			jwk = jwk.Public()
+		}
+		return printJSON(jwk)
+	case "jwks":
+		// Key sets are published, so private parameters are always dropped
+		if jwk.Kty == "oct" {
+			return fmt.Errorf("symmetric keys cannot be published in a JWKS")
+		}
+		return printJSON(key.JWKS{Keys: []key.JWK{*jwk.Public()}})
+	default:
+		return fmt.Errorf("unsupported format: %s", *to)
+	}
+}
+
+// runKeysThumbprint prints the RFC 7638 thumbprint of a key
+func (h *Handler) runKeysThumbprint(args []string) error {
+	flags := flag.NewFlagSet("keys thumbprint", flag.ContinueOnError)
+	kid := flags.String("kid", "", "Key to select when the input is a JWKS")
+	flags.Usage = func() {
+		fmt.Print(KeysUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := readKeyInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	jwk, err := key.ParseJWKFromAny(data, *kid)
+	if err != nil {
+		return fmt.Errorf("failed to parse key: %w", err)
+	}
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		return err
+	}
+	fmt.Println(thumbprint)
+	return nil
+}
+
+// readKeyInput reads key material from a file, or stdin for "" and "-"
+func readKeyInput(path string) ([]byte, error) {
+	if path == "" || path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key from stdin: %w", err)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	return data, nil
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// keyFile is a single generated output
+type keyFile struct {
+	suffix  string
+	data    []byte
+	private bool
+}
+
+// checkGeneratedKey rejects keys this tool could not sign or verify with:
+// the algorithm needs a hasher and must suit the key
+func checkGeneratedKey(jwk *key.JWK, algorithm string) error {
+	if _, err := hash.NewHasher(hash.Algorithm(algorithm)); err != nil {
+		return fmt.Errorf("no hasher supports %s: generate an rsa, ec (256 bits) or oct key", algorithm)
+	}
+	return jwk.CheckAlgorithm(algorithm)
+}
+
+// KeysUsageMessage is the help text for the keys command
+const KeysUsageMessage = `Usage:
+  jwt keys generate [flags]
+  jwt keys convert [flags] [file]
+  jwt keys thumbprint [flags] [file]
+
+Key files may be PEM, JWK or JWKS; the format is detected automatically.
+Without a file the key is read from stdin.
+
+Generate flags:
+  -type string
+        Key type: rsa, ec or oct (default "rsa")
+  -bits int
+        RSA modulus (default 2048), EC curve (256 only) or secret length
+        (default 256); keys without a hasher are rejected
+  -format string
+        Output format: pem, jwk or both (default "pem")
+  -alg string
+        JWS algorithm recorded in the JWK (default depends on type)
+  -out string
+        Write <out>.pem, <out>.pub.pem, <out>.jwk and <out>.pub.jwk
+        instead of printing to stdout
+
+Convert flags:
+  -to string
+        Output format: pem, jwk or jwks (default "jwk")
+  -kid string
+        Key to select when the input is a JWKS
+  -public
+        Output only the public key (jwks output is always public)
+  -alg string
+        JWS algorithm recorded in JWK output
+
+Thumbprint flags:
+  -kid string
+        Key to select when the input is a JWKS
+
+Private keys are written as PKCS#8 and public keys as PKIX ("PUBLIC KEY").
+The key ID (kid) is the RFC 7638 thumbprint of the public key.
+
+Examples:
+  jwt keys generate -type rsa -out signing
+  jwt keys generate -type ec -format jwk
+  jwt keys generate -type oct -bits 512
+  jwt keys convert -to jwks signing.pub.pem
+  jwt keys convert -to pem -kid 2024-01 jwks.json
+  jwt keys thumbprint signing.pub.pem
+`
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"jwt/internal/domain/jwt"
+	"jwt/internal/usecase/lint"
+)
+
+// runLint audits a decoded token and prints the findings
+func (h *Handler) runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	maxLifetime := flags.Duration("max-lifetime", 0, "Longest acceptable exp - iat (default 24h)")
+	disable := flags.String("disable", "", "Comma-separated rules to skip")
+	severities := flags.String("severity", "", "Comma-separated severity overrides, e.g. pii-claims=error,missing-typ=warning")
+	failOn := flags.String("fail-on", "error", "Exit with an error when a finding has at least this severity")
+	listRules := flags.Bool("rules", false, "List the rules and exit")
+	flags.Usage = func() {
+		fmt.Print(LintUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	threshold, err := lint.ParseSeverity(*failOn)
+	if err != nil {
+		return err
+	}
+	cfg := lint.Config{
+		SecretKey:   []byte(h.settings.SecretKey),
+		MaxLifetime: *maxLifetime,
+		Severities:  make(map[string]lint.Severity),
+	}
+	if *disable != "" {
+		cfg.Disabled = strings.Split(*disable, ",")
+	}
+	if *severities != "" {
+		for _, pair := range strings.Split(*severities, ",") {
+			name, level, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid -severity entry %q: expected rule=level", pair)
+			}
+			severity, err := lint.ParseSeverity(level)
+			if err != nil {
+				return err
+			}
+			cfg.Severities[name] = severity
+		}
+	}
+	linter := lint.New(cfg)
+
+	known := make(map[string]bool)
+	for _, rule := range linter.Rules() {
+		known[rule.Name] = true
+	}
+	for name := range cfg.Severities {
+		if !known[name] {
+			return fmt.Errorf("unknown lint rule %q", name)
+		}
+	}
+	for _, name := range cfg.Disabled {
+		if !known[name] {
+			return fmt.Errorf("unknown lint rule %q", name)
+		}
+	}
+
+	if *listRules {
+		for _, rule := range linter.Rules() {
+			fmt.Printf("%-20s %-8s %s\n", rule.Name, rule.Severity, rule.Description)
+		}
+		return nil
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Print(LintUsageMessage)
+		return fmt.Errorf("a token is required")
+	}
+	parser, ok := h.decoder.(jwt.Parser)
+	if !ok {
+		return fmt.Errorf("the configured decoder cannot parse tokens")
+	}
+	token, err := parser.Parse(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to decode JWT: %w", err)
+	}
+
+	findings := linter.Lint(token)
+	if len(findings) == 0 {
+		fmt.Println("No findings")
+		return nil
+	}
+	failures := 0
+	for _, f := range findings {
+		fmt.Printf("%-8s %s: %s\n", strings.ToUpper(f.Severity.String()), f.Rule, f.Message)
+		if f.Severity >= threshold {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("lint found %d finding(s) at or above %s", failures, threshold)
+	}
+	return nil
+}
+
+// LintUsageMessage is the help text for the lint command
+const LintUsageMessage = `Usage:
+  jwt lint [flags] <token>
+
+Audits a token's header and claims and reports findings by severity. The
+HMAC key strength check uses JWT_SECRET_KEY or the profile's secret key
+when one is configured.
+
+Flags:
+  -max-lifetime duration
+        Longest acceptable exp - iat (default 24h)
+  -disable string
+        Comma-separated rules to skip
+  -severity string
+        Comma-separated severity overrides, e.g. pii-claims=error
+  -fail-on string
+        Exit with an error when a finding has at least this severity
+        (info, warning, error) (default "error")
+  -rules
+        List the rules and exit
+
+Examples:
+  jwt lint eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+  jwt lint -disable pii-claims -max-lifetime 1h -fail-on warning eyJhbGciOi...
+`
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"jwt/internal/config"
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+)
+
+// Main parses the global flags, loads the settings and runs the command in
+// args (os.Args without the program name). It returns the process exit
+// code, so every entrypoint binary shares the same flag setup.
+func Main(args []string) int {
+	flags := flag.NewFlagSet("jwt", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Print(UsageMessage)
+	}
+
+	validateFlag := flags.Bool("validate", false, "Validate JWT signature")
+	algorithmFlag := flags.String("algorithm", "HS256", "Hash algorithm to use (HS256, HS384, HS512, RS256, PS256, ES256)")
+	generateFlag := flags.Bool("generate", false, "Generate a test JWT token")
+	profileFlag := flags.String("profile", "", "Named profile from the config file")
+	configFlag := flags.String("config", "", "Path to the config file")
+	issuerFlag := flags.String("issuer", "", "Expected token issuer (iss)")
+	audienceFlag := flags.String("audience", "", "Expected token audience (aud)")
+	leewayFlag := flags.Duration("leeway", 0, "Allowed clock skew when checking exp and nbf")
+	jwksURLFlag := flags.String("jwks-url", "", "Fetch verification keys from a JWKS URL")
+	denylistFlag := flags.String("denylist", "", "Reject tokens listed in this revocation file")
+	keyringFlag := flags.String("keyring", "", "Verify with the keys of a JSON keyring file")
+	x5cRootsFlag := flags.String("x5c-roots", "", "Verify with the token's x5c leaf certificate, trusting these root CAs")
+	keyURLsFlag := flags.String("trusted-key-urls", "", "Comma-separated URL prefixes jku and x5u headers may point to")
+	strictFlag := flags.Bool("strict", false, "Reject duplicate JSON members, non-canonical base64url and oversized tokens")
+	maxTokenSizeFlag := flags.Int("max-token-size", 0, "Size limit in bytes for -strict (default 16384)")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	// Get the command and args after flag parsing
+	args = flags.Args()
+	if len(args) < 1 && !*generateFlag {
+		fmt.Print(UsageMessage)
+		return 1
+	}
+
+	// Only flags given on the command line override the config file
+	overrides := config.Settings{
+		Issuer:       *issuerFlag,
+		Audience:     *audienceFlag,
+		Leeway:       *leewayFlag,
+		JWKSURL:      *jwksURLFlag,
+		Denylist:     *denylistFlag,
+		Keyring:      *keyringFlag,
+		X5CRoots:     *x5cRootsFlag,
+		KeyURLs:      *keyURLsFlag,
+		Strict:       *strictFlag,
+		MaxTokenSize: *maxTokenSizeFlag,
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "algorithm" {
+			overrides.Algorithm = *algorithmFlag
+		}
+	})
+
+	settings, err := LoadSettings(*configFlag, *profileFlag, overrides)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Parse algorithm (case-insensitive)
+	algorithm := hash.Algorithm(strings.ToUpper(settings.Algorithm))
+
+	decoder, err := NewDecoder(settings)
+	if errors.Is(err, hash.ErrUnsupportedAlgorithm) {
+		fmt.Fprintf(os.Stderr, "Error: invalid algorithm %s. Supported algorithms: HS256, HS384, HS512, RS256, PS256, ES256\n", algorithm)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	handler := NewHandler(decoder, WithSettings(settings))
+
+	if *generateFlag {
+		token, err := decoder.GenerateTestToken(algorithm)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating test token: %v\n", err)
+			return 1
+		}
+		fmt.Print("Test JWT Token:\n")
+		fmt.Print(token)
+		fmt.Print("\n\nSecret Key (for decoding):\n")
+		fmt.Print(jwt.TestSecretKey + "\n")
+		return 0
+	}
+
+	// Add validate flag to args if set
+	if *validateFlag {
+		args = append([]string{"-validate"}, args...)
+	}
+
+	if err := handler.Run(args...); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
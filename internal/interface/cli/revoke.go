@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"jwt/internal/interface/revocation"
+)
+
+// runRevoke dispatches the revoke subcommands
+func (h *Handler) runRevoke(args []string) error {
+	if len(args) < 1 {
+		fmt.Print(RevokeUsageMessage)
+		return nil
+	}
+
+	switch args[0] {
+	case "add", "remove":
+		return h.runRevokeEdit(args[0], args[1:])
+	case "list":
+		return h.runRevokeList(args[1:])
+	default:
+		return fmt.Errorf("unknown revoke command: %s", args[0])
+	}
+}
+
+// runRevokeEdit adds or removes a single denylist entry
+func (h *Handler) runRevokeEdit(action string, args []string) error {
+	flags := flag.NewFlagSet("revoke "+action, flag.ContinueOnError)
+	file := flags.String("file", h.settings.Denylist, "Denylist file")
+	jti := flags.String("jti", "", "Token ID (jti)")
+	sub := flags.String("sub", "", "Subject (sub)")
+	kid := flags.String("kid", "", "Signing key ID (kid)")
+	before := flags.String("before", "now", "With -sub, revoke tokens issued at or before this time (RFC 3339 or Unix seconds)")
+	flags.Usage = func() {
+		fmt.Print(RevokeUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	set := 0
+	for _, v := range []string{*jti, *sub, *kid} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of -jti, -sub or -kid is required")
+	}
+	path, err := denylistPath(*file)
+	if err != nil {
+		return err
+	}
+
+	list, err := revocation.Load(path)
+	if err != nil {
+		return err
+	}
+
+	changed := true
+	switch {
+	case action == "add" && *jti != "":
+		changed = list.RevokeJTI(*jti)
+	case action == "add" && *kid != "":
+		changed = list.RevokeKey(*kid)
+	case action == "add":
+		cutoff, err := parseCutoff(*before)
+		if err != nil {
+			return err
+		}
+		list.RevokeSubject(*sub, cutoff)
+	case *jti != "":
+		changed = list.RemoveJTI(*jti)
+	case *kid != "":
+		changed = list.RemoveKey(*kid)
+	default:
+		changed = list.RemoveSubject(*sub)
+	}
+
+	if !changed {
+		if action == "add" {
+			fmt.Println("Entry already revoked")
+		} else {
+			fmt.Println("Entry not found")
+		}
+		return nil
+	}
+	if err := revocation.Save(path, list); err != nil {
+		return err
+	}
+	if action == "add" {
+		fmt.Printf("Revoked; denylist written to %s\n", path)
+	} else {
+		fmt.Printf("Removed; denylist written to %s\n", path)
+	}
+	return nil
+}
+
+// runRevokeList prints the denylist entries
+func (h *Handler) runRevokeList(args []string) error {
+	flags := flag.NewFlagSet("revoke list", flag.ContinueOnError)
+	file := flags.String("file", h.settings.Denylist, "Denylist file")
+	flags.Usage = func() {
+		fmt.Print(RevokeUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	path, err := denylistPath(*file)
+	if err != nil {
+		return err
+	}
+
+	list, err := revocation.Load(path)
+	if err != nil {
+		return err
+	}
+	for _, jti := range list.JTIs {
+		fmt.Printf("jti  %s\n", jti)
+	}
+	subjects := make([]string, 0, len(list.Subjects))
+	for sub := range list.Subjects {
+		subjects = append(subjects, sub)
+	}
+	sort.Strings(subjects)
+	for _, sub := range subjects {
+		cutoff := time.Unix(list.Subjects[sub], 0).UTC().Format(time.RFC3339)
+		fmt.Printf("sub  %s (issued until %s)\n", sub, cutoff)
+	}
+	for _, kid := range list.KeyIDs {
+		fmt.Printf("kid  %s\n", kid)
+	}
+	return nil
+}
+
+// denylistPath checks that a denylist file was configured
+func denylistPath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("a denylist file is required: use -file, the global -denylist flag or a profile's denylist setting")
+	}
+	return path, nil
+}
+
+// parseCutoff accepts "now", an RFC 3339 timestamp or Unix seconds
+func parseCutoff(value string) (time.Time, error) {
+	if value == "now" {
+		return time.Now(), nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -before time %q: expected RFC 3339 or Unix seconds", value)
+	}
+	return t, nil
+}
+
+// RevokeUsageMessage is the help text for the revoke command
+const RevokeUsageMessage = `Usage:
+  jwt revoke add    [-file path] -jti ID | -sub SUBJECT [-before TIME] | -kid KID
+  jwt revoke remove [-file path] -jti ID | -sub SUBJECT | -kid KID
+  jwt revoke list   [-file path]
+
+Manages the denylist file consulted by "decode -validate" and the servers
+when a denylist is configured (global -denylist flag or a profile's
+denylist setting). Running validators pick up changes automatically.
+
+Entries:
+  -jti ID       Revoke a single token
+  -sub SUBJECT  Revoke every token for the subject issued at or before
+                -before (default now; RFC 3339 or Unix seconds)
+  -kid KID      Revoke every token signed with the key
+
+Flags:
+  -file string
+        Denylist file (default: the configured denylist)
+
+Examples:
+  jwt revoke add -file revoked.json -jti 5f1c0e7a
+  jwt -profile prod revoke add -sub user-123 -before 2024-05-01T00:00:00Z
+  jwt -profile prod revoke list
+`
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"jwt/internal/config"
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/jwks"
+	"jwt/internal/interface/revocation"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+// LoadSettings loads the config file, selects a profile and merges it with
+// the environment and the flags that were set on the command line
+func LoadSettings(configPath, profile string, flags config.Settings) (config.Settings, error) {
+	path := configPath
+	if path == "" {
+		defaultPath, err := config.DefaultPath()
+		if err != nil {
+			return config.Settings{}, err
+		}
+		path = defaultPath
+	}
+
+	var selected config.Profile
+	cfg, err := config.Load(path)
+	switch {
+	case err == nil:
+		if selected, err = cfg.Profile(profile); err != nil {
+			return config.Settings{}, err
+		}
+	case errors.Is(err, fs.ErrNotExist) && configPath == "" && profile == "":
+		// No config file is fine when nothing asks for one
+	default:
+		return config.Settings{}, err
+	}
+
+	return config.Resolve(selected, flags, os.Getenv), nil
+}
+
+// NewDecoder creates a decoder for the resolved settings
+func NewDecoder(settings config.Settings) (jwt.Decoder, error) {
+	hasher, err := hash.NewHasher(hash.Algorithm(settings.Algorithm))
+	if err != nil {
+		return nil, err
+	}
+	opts, err := decoderOptions(settings)
+	if err != nil {
+		return nil, err
+	}
+	return jwtusecase.NewDecoder(hasher, opts...), nil
+}
+
+// NewVerifier creates a verifier for the resolved settings
+func NewVerifier(settings config.Settings) (jwt.Verifier, error) {
+	hasher, err := hash.NewHasher(hash.Algorithm(settings.Algorithm))
+	if err != nil {
+		return nil, err
+	}
+	opts, err := decoderOptions(settings)
+	if err != nil {
+		return nil, err
+	}
+	return jwtusecase.NewVerifier(hasher, opts...), nil
+}
+
+// decoderOptions maps the settings onto the validation pipeline's key source,
+// policy and denylist. An x5c root bundle takes precedence over trusted
+// jku/x5u URLs, those over a JWKS URL, a JWKS URL over a keyring, and a
+// keyring over a static key.
+func decoderOptions(settings config.Settings) ([]jwtusecase.Option, error) {
+	if err := checkPublicKeySources(settings); err != nil {
+		return nil, err
+	}
+	opts := []jwtusecase.Option{
+		jwtusecase.WithPolicy(jwt.Policy{
+			Issuer:   settings.Issuer,
+			Audience: settings.Audience,
+			Leeway:   settings.Leeway,
+		}),
+	}
+	if settings.X5CRoots != "" {
+		roots, err := LoadCertPool(settings.X5CRoots)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, jwtusecase.WithKeySource(&jwt.CertificateChain{Roots: roots}))
+	} else if settings.KeyURLs != "" {
+		source, err := jwks.NewHeaderURLs(splitList(settings.KeyURLs))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, jwtusecase.WithKeySource(source))
+	} else if settings.JWKSURL != "" {
+		opts = append(opts, jwtusecase.WithKeySource(jwks.NewFetcher(settings.JWKSURL)))
+	} else if settings.Keyring != "" {
+		ring, err := LoadKeyring(settings.Keyring)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, jwtusecase.WithKeySource(ring))
+	} else if key := settings.VerificationKey(); len(key) > 0 {
+		opts = append(opts, jwtusecase.WithKey(key))
+	}
+	if settings.Strict {
+		opts = append(opts, jwtusecase.WithStrictParsing(settings.MaxTokenSize))
+	}
+	if settings.Denylist != "" {
+		opts = append(opts, jwtusecase.WithRevocationChecker(revocation.NewFileChecker(settings.Denylist)))
+	}
+	return opts, nil
+}
+
+// LoadKeyring reads a JSON keyring file:
+//
+//	{"current": "2024-06", "keys": [
+//	  {"kid": "2024-06", "key": "new-secret", "not_before": "2024-06-01T00:00:00Z"},
+//	  {"kid": "2024-01", "key": "old-secret", "not_after": "2024-07-01T00:00:00Z"}
+//	]}
+func LoadKeyring(path string) (*jwt.Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+	var ring jwt.Keyring
+	if err := json.Unmarshal(data, &ring); err != nil {
+		return nil, fmt.Errorf("invalid keyring %s: %w", path, err)
+	}
+	if err := ring.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &ring, nil
+}
+
+// LoadCertPool reads a bundle of PEM encoded root CA certificates
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read root CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("invalid root CA bundle %s: no PEM certificates found", path)
+	}
+	return pool, nil
+}
+
+// splitList splits a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// checkPublicKeySources rejects HMAC algorithms together with key sources
+// that publish public keys, which would otherwise be tried as secrets
+func checkPublicKeySources(settings config.Settings) error {
+	if !strings.HasPrefix(settings.Algorithm, "HS") {
+		return nil
+	}
+	if settings.JWKSURL != "" {
+		return fmt.Errorf("-jwks-url cannot be used with %s: use an RS, PS or ES algorithm", settings.Algorithm)
+	}
+	if settings.X5CRoots != "" {
+		return fmt.Errorf("-x5c-roots cannot be used with %s: use an RS, PS or ES algorithm", settings.Algorithm)
+	}
+	if settings.KeyURLs != "" {
+		return fmt.Errorf("-trusted-key-urls cannot be used with %s: use an RS, PS or ES algorithm", settings.Algorithm)
+	}
+	return nil
+}
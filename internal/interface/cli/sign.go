@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/signer"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+// EnvPKCS11PIN holds the user PIN for PKCS#11 signing, so it never appears
+// in the process list
+const EnvPKCS11PIN = "JWT_PKCS11_PIN"
+
+// runSign signs a JSON claims object with a key file, the HMAC secret or a
+// key on a PKCS#11 token
+func (h *Handler) runSign(args []string) error {
+	flags := flag.NewFlagSet("sign", flag.ContinueOnError)
+	keyFile := flags.String("key", "", "Private key file, PEM or JWK (RS/PS/ES algorithms)")
+	kid := flags.String("kid", "", "Key ID to put in the kid header")
+	module := flags.String("pkcs11-module", "", "PKCS#11 library to sign with instead of -key")
+	slot := flags.Uint("slot", 0, "PKCS#11 slot ID")
+	label := flags.String("key-label", "", "Label of the key pair on the PKCS#11 token")
+	flags.Usage = func() {
+		fmt.Print(SignUsageMessage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		fmt.Print(SignUsageMessage)
+		return fmt.Errorf("a JSON claims object is required")
+	}
+	claims, err := jwt.ParseObject([]byte(flags.Arg(0)))
+	if err != nil {
+		return fmt.Errorf("claims must be a JSON object: %w", err)
+	}
+
+	hasher, err := hash.NewHasher(hash.Algorithm(h.settings.Algorithm))
+	if err != nil {
+		return err
+	}
+	var opts []jwtusecase.EncoderOption
+	if *kid != "" {
+		opts = append(opts, jwtusecase.WithKeyID(*kid))
+	}
+
+	var encoder jwt.Encoder
+	switch {
+	case *module != "":
+		if *keyFile != "" {
+			return fmt.Errorf("-key and -pkcs11-module cannot be combined")
+		}
+		keyed, ok := hasher.(hash.KeyHasher)
+		if !ok || strings.HasPrefix(hasher.Name(), "HS") {
+			return fmt.Errorf("PKCS#11 signing requires an RS, PS or ES algorithm, not %s", hasher.Name())
+		}
+		hsm, err := signer.OpenPKCS11(signer.PKCS11Config{
+			Module:   *module,
+			Slot:     *slot,
+			KeyLabel: *label,
+			PIN:      os.Getenv(EnvPKCS11PIN),
+		})
+		if err != nil {
+			return err
+		}
+		defer hsm.Close()
+		encoder = jwtusecase.NewSignerEncoder(keyed, hsm, opts...)
+	case *keyFile != "":
+		key, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read signing key: %w", err)
+		}
+		encoder = jwtusecase.NewEncoder(hasher, key, opts...)
+	case strings.HasPrefix(hasher.Name(), "HS") && h.settings.SecretKey != "":
+		encoder = jwtusecase.NewEncoder(hasher, []byte(h.settings.SecretKey), opts...)
+	default:
+		return fmt.Errorf("a signing key is required: use -key, -pkcs11-module or JWT_SECRET_KEY for HMAC")
+	}
+
+	token, err := encoder.Encode(claims)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
+
+// SignUsageMessage is the help text for the sign command
+const SignUsageMessage = `Usage:
+  jwt [global flags] sign [flags] <claims-json>
+
+Signs a JSON claims object with the global -algorithm and prints the token.
+HMAC algorithms use JWT_SECRET_KEY (or the profile's secret); RS, PS and ES
+algorithms use -key or a key pair on a PKCS#11 token such as an HSM.
+
+Flags:
+  -key string
+        Private key file, PEM or JWK (RS/PS/ES algorithms)
+  -kid string
+        Key ID to put in the kid header
+  -pkcs11-module string
+        PKCS#11 library to sign with instead of -key
+  -slot uint
+        PKCS#11 slot ID
+  -key-label string
+        Label of the key pair on the PKCS#11 token
+
+The PKCS#11 user PIN is read from JWT_PKCS11_PIN. PKCS#11 support needs a
+binary built with cgo and "-tags pkcs11".
+
+Examples:
+  JWT_SECRET_KEY=... jwt sign '{"sub":"alice"}'
+  jwt -algorithm ES256 sign -key ec.pem -kid 2024-06 '{"sub":"alice"}'
+  JWT_PKCS11_PIN=1234 jwt -algorithm PS256 sign \
+      -pkcs11-module /usr/lib/softhsm/libsofthsm2.so -slot 0 -key-label jwt-signing '{"sub":"alice"}'
+`
@@ -0,0 +1,129 @@
+package grpcauth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+
+	"jwt/internal/domain/jwt"
+)
+
+// Defaults for TokenCredentials
+const (
+	// DefaultTTL is the lifetime of tokens issued for RPCs
+	DefaultTTL = 5 * time.Minute
+	// DefaultRefreshBefore is how long before expiry a token is replaced
+	DefaultRefreshBefore = 30 * time.Second
+)
+
+// TokenCredentials attaches a signed bearer token to every RPC. The token is
+// cached and re-signed shortly before it expires.
+type TokenCredentials struct {
+	encoder       jwt.Encoder
+	claims        func() map[string]any
+	ttl           time.Duration
+	refreshBefore time.Duration
+	insecure      bool
+	now           func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Compile-time check that TokenCredentials satisfies the gRPC interface
+var _ credentials.PerRPCCredentials = (*TokenCredentials)(nil)
+
+// CredentialsOption configures optional TokenCredentials behaviour
+type CredentialsOption func(*TokenCredentials)
+
+// WithTTL sets the lifetime of issued tokens
+func WithTTL(ttl time.Duration) CredentialsOption {
+	return func(c *TokenCredentials) {
+		c.ttl = ttl
+	}
+}
+
+// WithRefreshBefore sets how long before expiry a token is replaced
+func WithRefreshBefore(d time.Duration) CredentialsOption {
+	return func(c *TokenCredentials) {
+		c.refreshBefore = d
+	}
+}
+
+// WithInsecureTransport allows tokens to be sent over plaintext connections,
+// which is only appropriate for local testing
+func WithInsecureTransport() CredentialsOption {
+	return func(c *TokenCredentials) {
+		c.insecure = true
+	}
+}
+
+// WithClock sets the time source used for iat/exp and refresh decisions
+func WithClock(now func() time.Time) CredentialsOption {
+	return func(c *TokenCredentials) {
+		c.now = now
+	}
+}
+
+// NewTokenCredentials creates per-RPC credentials that sign claims with
+// encoder. claims is called for every new token; iat and exp are added.
+func NewTokenCredentials(encoder jwt.Encoder, claims func() map[string]any, opts ...CredentialsOption) *TokenCredentials {
+	c := &TokenCredentials{
+		encoder:       encoder,
+		claims:        claims,
+		ttl:           DefaultTTL,
+		refreshBefore: DefaultRefreshBefore,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// GetRequestMetadata returns the authorization metadata for an RPC
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := c.Token()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{authorizationKey: "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity reports whether the credentials need TLS
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return !c.insecure
+}
+
+// Token returns the cached token, signing a new one when it is missing or
+// about to expire
+func (c *TokenCredentials) Token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.token != "" && now.Before(c.expires.Add(-c.refreshBefore)) {
+		return c.token, nil
+	}
+
+	claims := map[string]any{}
+	if c.claims != nil {
+		for name, value := range c.claims() {
+			claims[name] = value
+		}
+	}
+	expires := now.Add(c.ttl)
+	claims["iat"] = now.Unix()
+	claims["exp"] = expires.Unix()
+
+	token, err := c.encoder.Encode(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign RPC token: %w", err)
+	}
+	c.token, c.expires = token, expires
+	return token, nil
+}
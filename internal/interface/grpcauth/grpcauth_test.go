@@ -0,0 +1,239 @@
+package grpcauth_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/grpcauth"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+var secret = []byte("grpc-test-secret-with-32-bytes!!!")
+
+func newPipeline(t *testing.T) (jwt.Encoder, jwt.Verifier) {
+	t.Helper()
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jwtusecase.NewEncoder(hasher, secret),
+		jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(secret), jwtusecase.WithPolicy(jwt.Policy{Audience: "orders"}))
+}
+
+func TestInterceptors_EndToEnd(t *testing.T) {
+	encoder, verifier := newPipeline(t)
+
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcauth.UnaryServerInterceptor(verifier)),
+		grpc.StreamInterceptor(grpcauth.StreamServerInterceptor(verifier)),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	dial := func(opts ...grpc.DialOption) healthpb.HealthClient {
+		opts = append(opts,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return healthpb.NewHealthClient(conn)
+	}
+
+	creds := grpcauth.NewTokenCredentials(encoder,
+		func() map[string]any { return map[string]any{"sub": "billing", "aud": "orders"} },
+		grpcauth.WithInsecureTransport(),
+	)
+	authed := dial(grpc.WithPerRPCCredentials(creds))
+	anonymous := dial()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := authed.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("Unary call with credentials failed: %v", err)
+	}
+	if _, err := anonymous.Check(ctx, &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without credentials, got %v", err)
+	}
+
+	stream, err := authed.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		t.Errorf("Streaming call with credentials failed: %v", err)
+	}
+	stream, err = anonymous.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated stream without credentials, got %v", err)
+	}
+}
+
+func TestUnaryServerInterceptor_Claims(t *testing.T) {
+	encoder, verifier := newPipeline(t)
+	interceptor := grpcauth.UnaryServerInterceptor(verifier)
+	valid, _ := encoder.Encode(map[string]any{"sub": "billing", "aud": "orders"})
+	wrongAudience, _ := encoder.Encode(map[string]any{"sub": "billing", "aud": "users"})
+
+	tests := []struct {
+		name          string
+		authorization []string
+		wantSub       string
+	}{
+		{"Valid token", []string{"Bearer " + valid}, "billing"},
+		{"Missing metadata", nil, ""},
+		{"Wrong scheme", []string{"Basic " + valid}, ""},
+		{"Policy violation", []string{"Bearer " + wrongAudience}, ""},
+		{"Duplicate metadata", []string{"Bearer " + valid, "Bearer " + valid}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := metadata.MD{}
+			for _, v := range tt.authorization {
+				md.Append("authorization", v)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			var gotSub any
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+				claims, _ := grpcauth.ClaimsFromContext(ctx)
+				gotSub = claims["sub"]
+				return nil, nil
+			})
+
+			if tt.wantSub == "" {
+				if status.Code(err) != codes.Unauthenticated {
+					t.Errorf("Expected Unauthenticated, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if gotSub != tt.wantSub {
+				t.Errorf("Expected sub %q in context, got %v", tt.wantSub, gotSub)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor_HidesVerificationErrors(t *testing.T) {
+	encoder, verifier := newPipeline(t)
+	var errorLog bytes.Buffer
+	interceptor := grpcauth.UnaryServerInterceptor(verifier, grpcauth.WithErrorLog(log.New(&errorLog, "", 0)))
+	wrongAudience, _ := encoder.Encode(map[string]any{"sub": "billing", "aud": "users"})
+	expired, _ := encoder.Encode(map[string]any{"sub": "billing", "aud": "orders", "exp": time.Now().Add(-time.Hour).Unix()})
+
+	tests := []struct {
+		name        string
+		token       string
+		wantMessage string
+		wantLog     string
+	}{
+		{"Policy violation", wrongAudience, "invalid token", "invalid audience: users"},
+		{"Expired", expired, "token expired", "token is expired"},
+		{"Malformed", "not-a-jwt", "malformed token", "expected 3 parts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errorLog.Reset()
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tt.token))
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Get"}, func(ctx context.Context, req any) (any, error) {
+				return nil, nil
+			})
+
+			st := status.Convert(err)
+			if st.Code() != codes.Unauthenticated || st.Message() != tt.wantMessage {
+				t.Errorf("Expected Unauthenticated %q, got %v", tt.wantMessage, err)
+			}
+			if strings.Contains(st.Message(), tt.wantLog) {
+				t.Errorf("Status message %q leaks the underlying error", st.Message())
+			}
+			if !strings.Contains(errorLog.String(), tt.wantLog) || !strings.Contains(errorLog.String(), "/orders.Orders/Get") {
+				t.Errorf("Expected the error log to contain %q and the method, got %q", tt.wantLog, errorLog.String())
+			}
+		})
+	}
+}
+
+func TestTokenCredentials_RefreshBeforeExpiry(t *testing.T) {
+	encoder, _ := newPipeline(t)
+	now := time.Unix(1700000000, 0)
+	creds := grpcauth.NewTokenCredentials(encoder,
+		func() map[string]any { return map[string]any{"aud": "orders"} },
+		grpcauth.WithTTL(time.Minute),
+		grpcauth.WithRefreshBefore(10*time.Second),
+		grpcauth.WithClock(func() time.Time { return now }),
+	)
+
+	if !creds.RequireTransportSecurity() {
+		t.Error("Expected transport security to be required by default")
+	}
+
+	first, err := creds.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(49 * time.Second)
+	second, _ := creds.GetRequestMetadata(context.Background())
+	if first["authorization"] != second["authorization"] {
+		t.Error("Expected the cached token to be reused before the refresh window")
+	}
+
+	now = now.Add(2 * time.Second)
+	third, _ := creds.GetRequestMetadata(context.Background())
+	if third["authorization"] == second["authorization"] {
+		t.Error("Expected a new token inside the refresh window")
+	}
+
+	token, err := creds.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := jwtusecase.NewVerifier(mustHasher(t), jwtusecase.WithKey(secret),
+		jwtusecase.WithPolicy(jwt.Policy{Now: func() time.Time { return now }})).Verify(token)
+	if err != nil {
+		t.Fatalf("Issued token did not verify: %v", err)
+	}
+	if parsed.Claims["exp"] != json.Number(strconv.FormatInt(now.Add(time.Minute).Unix(), 10)) {
+		t.Errorf("Unexpected exp claim: %v", parsed.Claims["exp"])
+	}
+}
+
+func mustHasher(t *testing.T) hash.Hasher {
+	t.Helper()
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hasher
+}
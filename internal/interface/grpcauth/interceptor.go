@@ -0,0 +1,132 @@
+// Package grpcauth authenticates gRPC calls with bearer tokens, on the
+// server through interceptors and on the client through per-RPC credentials
+package grpcauth
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"jwt/internal/domain/jwt"
+)
+
+// authorizationKey is the metadata key carrying the bearer token
+const authorizationKey = "authorization"
+
+// interceptorOptions holds the optional interceptor settings
+type interceptorOptions struct {
+	errorLog *log.Logger
+}
+
+// Option configures optional interceptor behaviour
+type Option func(*interceptorOptions)
+
+// WithErrorLog sets the logger receiving the details of rejected tokens,
+// which callers only see as a fixed status message. The log package's
+// standard logger is used by default.
+func WithErrorLog(logger *log.Logger) Option {
+	return func(o *interceptorOptions) {
+		o.errorLog = logger
+	}
+}
+
+// newInterceptorOptions applies opts to the defaults
+func newInterceptorOptions(opts []Option) *interceptorOptions {
+	o := &interceptorOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// logf reports a rejected call to the error log
+func (o *interceptorOptions) logf(format string, args ...any) {
+	if o.errorLog != nil {
+		o.errorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// UnaryServerInterceptor authenticates unary RPCs with the bearer token in
+// the authorization metadata. The verified token is available to handlers
+// through TokenFromContext.
+func UnaryServerInterceptor(verifier jwt.Verifier, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newInterceptorOptions(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, verifier, info.FullMethod, o)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streaming RPCs with the bearer token
+// in the authorization metadata
+func StreamServerInterceptor(verifier jwt.Verifier, opts ...Option) grpc.StreamServerInterceptor {
+	o := newInterceptorOptions(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), verifier, info.FullMethod, o)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// TokenFromContext returns the verified token stored by the interceptors
+func TokenFromContext(ctx context.Context) (*jwt.Token, bool) {
+	return jwt.FromContext(ctx)
+}
+
+// ClaimsFromContext returns the verified claims stored by the interceptors
+func ClaimsFromContext(ctx context.Context) (map[string]any, bool) {
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return token.Claims, true
+}
+
+// authenticate verifies the request's token and returns a context carrying
+// it. Verification errors are logged and answered with a fixed description.
+func authenticate(ctx context.Context, verifier jwt.Verifier, method string, o *interceptorOptions) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+	if len(values) > 1 {
+		return nil, status.Error(codes.Unauthenticated, "multiple authorization metadata values")
+	}
+
+	scheme, raw, ok := strings.Cut(values[0], " ")
+	raw = strings.TrimSpace(raw)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || raw == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata must use the Bearer scheme")
+	}
+
+	token, err := verifier.Verify(raw)
+	if err != nil {
+		o.logf("grpcauth: rejected token for %s: %v", method, err)
+		return nil, status.Error(codes.Unauthenticated, jwt.Describe(err))
+	}
+	return jwt.NewContext(ctx, token), nil
+}
+
+// authenticatedStream overrides the stream context with the authenticated one
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the verified token
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,125 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+// The PEM benchmarks measure Sign/Verify, which parse the key on every call;
+// the Parsed benchmarks measure SignWithKey/VerifyWithKey with a key parsed
+// once up front.
+
+var benchData = []byte(strings.Repeat("eyJhbGciOiJSUzI1NiJ9", 10) + "." + strings.Repeat("eyJzdWIiOiJ1c2VyIn0", 20))
+
+func benchRSAKeys(b *testing.B) (privatePEM, publicPEM []byte) {
+	b.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		b.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	privatePEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return privatePEM, publicPEM
+}
+
+func BenchmarkRS256Verify_PEM(b *testing.B) {
+	hasher := &RS256Hasher{}
+	privatePEM, publicPEM := benchRSAKeys(b)
+	signature, err := hasher.Sign(benchData, privatePEM)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := hasher.Verify(benchData, signature, publicPEM); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRS256Verify_Parsed(b *testing.B) {
+	hasher := &RS256Hasher{}
+	privatePEM, publicPEM := benchRSAKeys(b)
+	signature, err := hasher.Sign(benchData, privatePEM)
+	if err != nil {
+		b.Fatal(err)
+	}
+	publicKey, err := hasher.ParseVerificationKey(publicPEM)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := hasher.VerifyWithKey(benchData, signature, publicKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRS256Sign_PEM(b *testing.B) {
+	hasher := &RS256Hasher{}
+	privatePEM, _ := benchRSAKeys(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := hasher.Sign(benchData, privatePEM); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRS256Sign_Parsed(b *testing.B) {
+	hasher := &RS256Hasher{}
+	privatePEM, _ := benchRSAKeys(b)
+	privateKey, err := hasher.ParseSigningKey(privatePEM)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := hasher.SignWithKey(benchData, privateKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHS256Verify_Bytes(b *testing.B) {
+	hasher := &HS256Hasher{}
+	secret := []byte(strings.Repeat("k", HS256MinKeySize))
+	signature, err := hasher.Sign(benchData, secret)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := hasher.Verify(benchData, signature, secret); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHS256Verify_Parsed(b *testing.B) {
+	hasher := &HS256Hasher{}
+	secret := []byte(strings.Repeat("k", HS256MinKeySize))
+	signature, err := hasher.Sign(benchData, secret)
+	if err != nil {
+		b.Fatal(err)
+	}
+	key, err := hasher.ParseVerificationKey(secret)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := hasher.VerifyWithKey(benchData, signature, key); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
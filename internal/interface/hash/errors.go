@@ -0,0 +1,13 @@
+package hash
+
+import "errors"
+
+// Errors reported by the hashers; callers match them with errors.Is
+var (
+	// ErrInvalidKey means the key cannot be used with the algorithm
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrSignatureInvalid means the signature does not match the data
+	ErrSignatureInvalid = errors.New("invalid signature")
+	// ErrUnsupportedAlgorithm means no hasher implements the algorithm
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+)
@@ -0,0 +1,127 @@
+package hash
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	keyparse "jwt/internal/domain/key"
+)
+
+// es256Size is the length of an ES256 signature: r and s as 32-byte
+// big-endian integers (RFC 7518 section 3.4)
+const es256Size = 64
+
+// ES256Hasher implements the Hasher interface for the ES256 algorithm
+// (ECDSA using P-256 and SHA-256)
+type ES256Hasher struct{}
+
+// Sign signs the data using ES256 algorithm. The key is parsed on every
+// call; use ParseSigningKey and SignWithKey to parse it once.
+func (h *ES256Hasher) Sign(data, key []byte) ([]byte, error) {
+	privateKey, err := h.ParseSigningKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return h.SignWithKey(data, privateKey)
+}
+
+// Verify verifies the signature using ES256 algorithm, returning
+// ErrSignatureInvalid when it does not match
+func (h *ES256Hasher) Verify(data, signature, key []byte) error {
+	publicKey, err := h.ParseVerificationKey(key)
+	if err != nil {
+		return err
+	}
+	return h.VerifyWithKey(data, signature, publicKey)
+}
+
+// ParseSigningKey parses a PEM or JWK P-256 private key into an
+// *ecdsa.PrivateKey
+func (h *ES256Hasher) ParseSigningKey(key []byte) (crypto.PrivateKey, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%w: empty EC private key", ErrInvalidKey)
+	}
+	privateKey, err := keyparse.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	ecKey, ok := privateKey.(*ecdsa.PrivateKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: ES256 requires a P-256 private key", ErrInvalidKey)
+	}
+	return ecKey, nil
+}
+
+// ParseVerificationKey parses a PEM or JWK P-256 public key into an
+// *ecdsa.PublicKey
+func (h *ES256Hasher) ParseVerificationKey(key []byte) (crypto.PublicKey, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%w: empty EC public key", ErrInvalidKey)
+	}
+	publicKey, err := keyparse.ParsePublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	ecKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: ES256 requires a P-256 public key", ErrInvalidKey)
+	}
+	return ecKey, nil
+}
+
+// SignWithKey signs the data with an *ecdsa.PrivateKey or any crypto.Signer
+// holding a P-256 key. The signer's ASN.1 signature is converted to the
+// fixed-length form JWS uses.
+func (h *ES256Hasher) SignWithKey(data []byte, key crypto.PrivateKey) ([]byte, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: ES256 requires an *ecdsa.PrivateKey or crypto.Signer, got %T", ErrInvalidKey, key)
+	}
+	if public, ok := signer.Public().(*ecdsa.PublicKey); !ok || public.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: ES256 requires a P-256 key", ErrInvalidKey)
+	}
+
+	hashed := sha256.Sum256(data)
+	der, err := signer.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	if err != nil {
+		return nil, signerError(h.Name(), signer, err)
+	}
+	var sig struct{ R, S *big.Int }
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) > 0 || sig.R.BitLen() > 256 || sig.S.BitLen() > 256 {
+		return nil, fmt.Errorf("ES256 signer returned a malformed signature")
+	}
+	signature := make([]byte, es256Size)
+	sig.R.FillBytes(signature[:es256Size/2])
+	sig.S.FillBytes(signature[es256Size/2:])
+	return signature, nil
+}
+
+// VerifyWithKey verifies the signature with an *ecdsa.PublicKey
+func (h *ES256Hasher) VerifyWithKey(data, signature []byte, key crypto.PublicKey) error {
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok || publicKey.Curve != elliptic.P256() {
+		return fmt.Errorf("%w: ES256 requires a P-256 *ecdsa.PublicKey, got %T", ErrInvalidKey, key)
+	}
+	if len(signature) != es256Size {
+		return ErrSignatureInvalid
+	}
+
+	r := new(big.Int).SetBytes(signature[:es256Size/2])
+	s := new(big.Int).SetBytes(signature[es256Size/2:])
+	hashed := sha256.Sum256(data)
+	if !ecdsa.Verify(publicKey, hashed[:], r, s) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Name returns the name of the hashing algorithm
+func (h *ES256Hasher) Name() string {
+	return "ES256"
+}
@@ -0,0 +1,78 @@
+package hash
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	keyparse "jwt/internal/domain/key"
+)
+
+// opaqueSigner hides the concrete key type, like a KMS or HSM client
+type opaqueSigner struct{ crypto.Signer }
+
+func TestES256Hasher_ExternalSigner(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := &ES256Hasher{}
+	data := []byte("header.payload")
+
+	// The signer's ASN.1 output is converted to the fixed-length JWS form
+	signature, err := hasher.SignWithKey(data, opaqueSigner{privateKey})
+	if err != nil {
+		t.Fatalf("SignWithKey failed: %v", err)
+	}
+	if len(signature) != es256Size {
+		t.Fatalf("Expected a %d-byte signature, got %d", es256Size, len(signature))
+	}
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	hashed := sha256.Sum256(data)
+	if !ecdsa.Verify(&privateKey.PublicKey, hashed[:], r, s) {
+		t.Error("Signature does not verify with crypto/ecdsa")
+	}
+}
+
+func TestES256Hasher_RoundTrip(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePEM, _ := keyparse.MarshalPrivateKeyPEM(privateKey)
+	publicPEM, _ := keyparse.MarshalPublicKeyPEM(&privateKey.PublicKey)
+	hasher := &ES256Hasher{}
+	data := []byte("header.payload")
+
+	signature, err := hasher.Sign(data, privatePEM)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if len(signature) != es256Size {
+		t.Errorf("Expected a %d-byte signature, got %d", es256Size, len(signature))
+	}
+	if err := hasher.Verify(data, signature, publicPEM); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
+	}
+	if err := hasher.Verify(data, signature[:es256Size-1], publicPEM); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for a truncated signature, got %v", err)
+	}
+
+	// Only P-256 keys are accepted
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hasher.SignWithKey(data, p384); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for a P-384 key, got %v", err)
+	}
+	if err := hasher.VerifyWithKey(data, signature, &p384.PublicKey); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for a P-384 public key, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHMACKey_RoundTrip(t *testing.T) {
+	hasher := &HS256Hasher{}
+	secret := []byte(strings.Repeat("k", HS256MinKeySize))
+	data := []byte("header.payload")
+
+	signingKey, err := hasher.ParseSigningKey(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationKey, err := hasher.ParseVerificationKey(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := hasher.SignWithKey(data, signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both paths must produce interchangeable signatures
+	if err := hasher.Verify(data, signature, secret); err != nil {
+		t.Errorf("Verify rejected a SignWithKey signature: %v", err)
+	}
+	legacy, _ := hasher.Sign(data, secret)
+	if err := hasher.VerifyWithKey(data, legacy, verificationKey); err != nil {
+		t.Errorf("VerifyWithKey rejected a Sign signature: %v", err)
+	}
+
+	// The handle keeps its own copy of the secret
+	secret[0] = 'x'
+	if err := hasher.VerifyWithKey(data, signature, verificationKey); err != nil {
+		t.Errorf("Key handle changed with the caller's buffer: %v", err)
+	}
+	if err := hasher.VerifyWithKey([]byte("tampered"), signature, verificationKey); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+	}
+}
+
+func TestHMACKey_Invalid(t *testing.T) {
+	hs256 := &HS256Hasher{}
+	hs512 := &HS512Hasher{}
+	data := []byte("header.payload")
+
+	if _, err := hs256.ParseSigningKey([]byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for a short signing key, got %v", err)
+	}
+	if _, err := hs256.ParseVerificationKey(nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for an empty verification key, got %v", err)
+	}
+
+	// Short keys may verify existing tokens but cannot sign
+	short, err := hs256.ParseVerificationKey([]byte("short"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hs256.SignWithKey(data, short); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey signing with a short key, got %v", err)
+	}
+
+	// A handle is bound to the algorithm that prepared it
+	key, err := hs512.ParseVerificationKey([]byte(strings.Repeat("k", HS512MinKeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hs256.VerifyWithKey(data, nil, key); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for an HS512 key used with HS256, got %v", err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hs256.VerifyWithKey(data, nil, &rsaKey.PublicKey); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for an RSA key, got %v", err)
+	}
+	if err := (&RS256Hasher{}).VerifyWithKey(data, nil, key); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for an HMAC key used with RS256, got %v", err)
+	}
+}
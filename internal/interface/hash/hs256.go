@@ -1,28 +1,48 @@
 package hash
 
 import (
-	"crypto/hmac"
+	"crypto"
 	"crypto/sha256"
-	"encoding/base64"
 )
 
+// HS256MinKeySize is the shortest key accepted for signing; RFC 7518
+// section 3.2 requires a key at least as long as the hash output
+const HS256MinKeySize = sha256.Size
+
 // HS256Hasher implements the Hasher interface using HMAC-SHA256
 type HS256Hasher struct{}
 
-// Sign signs the data using HS256 algorithm
-func (h *HS256Hasher) Sign(data []byte, key []byte) string {
-	if len(key) == 0 {
-		return ""
-	}
-	mac := hmac.New(sha256.New, key)
-	mac.Write(data)
-	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+// Sign signs the data using HS256 algorithm. Keys shorter than
+// HS256MinKeySize are rejected with ErrInvalidKey.
+func (h *HS256Hasher) Sign(data, key []byte) ([]byte, error) {
+	return signMAC(sha256.New, data, key, HS256MinKeySize)
+}
+
+// Verify verifies the signature using HS256 algorithm, returning
+// ErrSignatureInvalid when it does not match
+func (h *HS256Hasher) Verify(data, signature, key []byte) error {
+	return verifyMAC(sha256.New, data, signature, key)
+}
+
+// ParseSigningKey prepares key for repeated SignWithKey calls. Keys shorter
+// than HS256MinKeySize are rejected with ErrInvalidKey.
+func (h *HS256Hasher) ParseSigningKey(key []byte) (crypto.PrivateKey, error) {
+	return parseMACSigningKey(h.Name(), sha256.New, key, HS256MinKeySize)
+}
+
+// ParseVerificationKey prepares key for repeated VerifyWithKey calls
+func (h *HS256Hasher) ParseVerificationKey(key []byte) (crypto.PublicKey, error) {
+	return parseMACVerificationKey(h.Name(), sha256.New, key)
+}
+
+// SignWithKey signs the data with a key from ParseSigningKey
+func (h *HS256Hasher) SignWithKey(data []byte, key crypto.PrivateKey) ([]byte, error) {
+	return signWithMACKey(h.Name(), data, key, HS256MinKeySize)
 }
 
-// Verify verifies the signature using HS256 algorithm
-func (h *HS256Hasher) Verify(data []byte, signature string, key []byte) bool {
-	expected := h.Sign(data, key)
-	return hmac.Equal([]byte(expected), []byte(signature))
+// VerifyWithKey verifies the signature with a key from ParseVerificationKey
+func (h *HS256Hasher) VerifyWithKey(data, signature []byte, key crypto.PublicKey) error {
+	return verifyWithMACKey(h.Name(), data, signature, key)
 }
 
 // Name returns the name of the hashing algorithm
@@ -1,28 +1,48 @@
 package hash
 
 import (
-	"crypto/hmac"
+	"crypto"
 	"crypto/sha512"
-	"encoding/base64"
 )
 
+// HS384MinKeySize is the shortest key accepted for signing; RFC 7518
+// section 3.2 requires a key at least as long as the hash output
+const HS384MinKeySize = sha512.Size384
+
 // HS384Hasher implements the Hasher interface using HMAC-SHA384
 type HS384Hasher struct{}
 
-// Sign signs the data using HS384 algorithm
-func (h *HS384Hasher) Sign(data []byte, key []byte) string {
-	if len(key) == 0 {
-		return ""
-	}
-	mac := hmac.New(sha512.New384, key)
-	mac.Write(data)
-	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+// Sign signs the data using HS384 algorithm. Keys shorter than
+// HS384MinKeySize are rejected with ErrInvalidKey.
+func (h *HS384Hasher) Sign(data, key []byte) ([]byte, error) {
+	return signMAC(sha512.New384, data, key, HS384MinKeySize)
+}
+
+// Verify verifies the signature using HS384 algorithm, returning
+// ErrSignatureInvalid when it does not match
+func (h *HS384Hasher) Verify(data, signature, key []byte) error {
+	return verifyMAC(sha512.New384, data, signature, key)
+}
+
+// ParseSigningKey prepares key for repeated SignWithKey calls. Keys shorter
+// than HS384MinKeySize are rejected with ErrInvalidKey.
+func (h *HS384Hasher) ParseSigningKey(key []byte) (crypto.PrivateKey, error) {
+	return parseMACSigningKey(h.Name(), sha512.New384, key, HS384MinKeySize)
+}
+
+// ParseVerificationKey prepares key for repeated VerifyWithKey calls
+func (h *HS384Hasher) ParseVerificationKey(key []byte) (crypto.PublicKey, error) {
+	return parseMACVerificationKey(h.Name(), sha512.New384, key)
+}
+
+// SignWithKey signs the data with a key from ParseSigningKey
+func (h *HS384Hasher) SignWithKey(data []byte, key crypto.PrivateKey) ([]byte, error) {
+	return signWithMACKey(h.Name(), data, key, HS384MinKeySize)
 }
 
-// Verify verifies the signature using HS384 algorithm
-func (h *HS384Hasher) Verify(data []byte, signature string, key []byte) bool {
-	expected := h.Sign(data, key)
-	return hmac.Equal([]byte(expected), []byte(signature))
+// VerifyWithKey verifies the signature with a key from ParseVerificationKey
+func (h *HS384Hasher) VerifyWithKey(data, signature []byte, key crypto.PublicKey) error {
+	return verifyWithMACKey(h.Name(), data, signature, key)
 }
 
 // Name returns the name of the hashing algorithm
@@ -0,0 +1,82 @@
+package hash
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+)
+
+// pssOptions are the RSASSA-PSS parameters of RFC 7518 section 3.5: MGF1
+// with SHA-256 and a salt as long as the hash
+var pssOptions = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+
+// PS256Hasher implements the Hasher interface for the PS256 algorithm
+// (RSASSA-PSS using SHA-256)
+type PS256Hasher struct{}
+
+// Sign signs the data using PS256 algorithm. The key is parsed on every
+// call; use ParseSigningKey and SignWithKey to parse it once.
+func (h *PS256Hasher) Sign(data, key []byte) ([]byte, error) {
+	privateKey, err := h.ParseSigningKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return h.SignWithKey(data, privateKey)
+}
+
+// Verify verifies the signature using PS256 algorithm, returning
+// ErrSignatureInvalid when it does not match
+func (h *PS256Hasher) Verify(data, signature, key []byte) error {
+	publicKey, err := h.ParseVerificationKey(key)
+	if err != nil {
+		return err
+	}
+	return h.VerifyWithKey(data, signature, publicKey)
+}
+
+// ParseSigningKey parses a PEM or JWK private key into an *rsa.PrivateKey
+func (h *PS256Hasher) ParseSigningKey(key []byte) (crypto.PrivateKey, error) {
+	return (&RS256Hasher{}).ParseSigningKey(key)
+}
+
+// ParseVerificationKey parses a PEM or JWK public key into an *rsa.PublicKey
+func (h *PS256Hasher) ParseVerificationKey(key []byte) (crypto.PublicKey, error) {
+	return (&RS256Hasher{}).ParseVerificationKey(key)
+}
+
+// SignWithKey signs the data with an *rsa.PrivateKey or any crypto.Signer
+// holding an RSA key that supports PSS
+func (h *PS256Hasher) SignWithKey(data []byte, key crypto.PrivateKey) ([]byte, error) {
+	signer, err := rsaSigner(h.Name(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed := sha256.Sum256(data)
+	signature, err := signer.Sign(rand.Reader, hashed[:], pssOptions)
+	if err != nil {
+		return nil, signerError(h.Name(), signer, err)
+	}
+	return signature, nil
+}
+
+// VerifyWithKey verifies the signature with an *rsa.PublicKey
+func (h *PS256Hasher) VerifyWithKey(data, signature []byte, key crypto.PublicKey) error {
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: PS256 requires an *rsa.PublicKey, got %T", ErrInvalidKey, key)
+	}
+
+	hashed := sha256.Sum256(data)
+	if err := rsa.VerifyPSS(publicKey, crypto.SHA256, hashed[:], signature, pssOptions); err != nil {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Name returns the name of the hashing algorithm
+func (h *PS256Hasher) Name() string {
+	return "PS256"
+}
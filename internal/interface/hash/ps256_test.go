@@ -0,0 +1,34 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+func TestPS256Hasher_RoundTrip(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := &PS256Hasher{}
+	data := []byte("header.payload")
+
+	signature, err := hasher.SignWithKey(data, privateKey)
+	if err != nil {
+		t.Fatalf("SignWithKey failed: %v", err)
+	}
+	if err := hasher.VerifyWithKey(data, signature, &privateKey.PublicKey); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
+	}
+
+	// PSS and PKCS #1 v1.5 signatures are not interchangeable
+	pkcs1, err := (&RS256Hasher{}).SignWithKey(data, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hasher.VerifyWithKey(data, pkcs1, &privateKey.PublicKey); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for an RS256 signature, got %v", err)
+	}
+}
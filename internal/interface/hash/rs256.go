@@ -2,82 +2,119 @@ package hash
 
 import (
 	"crypto"
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
-	"crypto/x509"
-	"encoding/base64"
-	"encoding/pem"
+	"fmt"
+
+	keyparse "jwt/internal/domain/key"
 )
 
 // RS256Hasher implements the Hasher interface for RS256 algorithm
 type RS256Hasher struct{}
 
-// Sign signs the data using RS256 algorithm
-func (h *RS256Hasher) Sign(data []byte, key []byte) string {
-	if len(key) == 0 {
-		return ""
-	}
-
-	// Parse the private key
-	block, _ := pem.Decode(key)
-	if block == nil {
-		return ""
+// Sign signs the data using RS256 algorithm. The key is parsed on every
+// call; use ParseSigningKey and SignWithKey to parse it once.
+func (h *RS256Hasher) Sign(data, key []byte) ([]byte, error) {
+	privateKey, err := h.ParseSigningKey(key)
+	if err != nil {
+		return nil, err
 	}
+	return h.SignWithKey(data, privateKey)
+}
 
-	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+// Verify verifies the signature using RS256 algorithm, returning
+// ErrSignatureInvalid when it does not match. The key is parsed on every
+// call; use ParseVerificationKey and VerifyWithKey to parse it once.
+func (h *RS256Hasher) Verify(data, signature, key []byte) error {
+	publicKey, err := h.ParseVerificationKey(key)
 	if err != nil {
-		return ""
+		return err
 	}
+	return h.VerifyWithKey(data, signature, publicKey)
+}
 
-	// For JWT signing, we need to hash the data first
-	hasher := sha256.New()
-	hasher.Write(data)
-	hashedData := hasher.Sum(nil)
-
-	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashedData)
+// ParseSigningKey parses a PEM or JWK private key into an *rsa.PrivateKey
+func (h *RS256Hasher) ParseSigningKey(key []byte) (crypto.PrivateKey, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%w: empty RSA private key", ErrInvalidKey)
+	}
+	privateKey, err := keyparse.ParseRSAPrivateKey(key)
 	if err != nil {
-		return ""
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
 	}
-
-	return base64.RawURLEncoding.EncodeToString(signature)
+	return privateKey, nil
 }
 
-// Verify verifies the signature using RS256 algorithm
-func (h *RS256Hasher) Verify(data []byte, signature string, key []byte) bool {
+// ParseVerificationKey parses a PEM or JWK public key into an *rsa.PublicKey
+func (h *RS256Hasher) ParseVerificationKey(key []byte) (crypto.PublicKey, error) {
 	if len(key) == 0 {
-		return false
+		return nil, fmt.Errorf("%w: empty RSA public key", ErrInvalidKey)
 	}
-
-	// Parse the public key
-	block, _ := pem.Decode(key)
-	if block == nil {
-		return false
+	publicKey, err := keyparse.ParseRSAPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
 	}
+	return publicKey, nil
+}
 
-	publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
+// SignWithKey signs the data with an *rsa.PrivateKey or any crypto.Signer
+// holding an RSA key, such as a KMS or HSM client
+func (h *RS256Hasher) SignWithKey(data []byte, key crypto.PrivateKey) ([]byte, error) {
+	signer, err := rsaSigner(h.Name(), key)
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	// Decode the signature
-	signatureBytes, err := base64.RawURLEncoding.DecodeString(signature)
+	// For JWT signing, we need to hash the data first
+	hashed := sha256.Sum256(data)
+	signature, err := signer.Sign(rand.Reader, hashed[:], crypto.SHA256)
 	if err != nil {
-		return false
+		return nil, signerError(h.Name(), signer, err)
 	}
+	return signature, nil
+}
 
-	// For JWT verification, we need to hash the data first
-	hasher := sha256.New()
-	hasher.Write(data)
-	hashedData := hasher.Sum(nil)
-
-	// Verify the signature
-	err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashedData, signatureBytes)
+// VerifyWithKey verifies the signature with an *rsa.PublicKey
+func (h *RS256Hasher) VerifyWithKey(data, signature []byte, key crypto.PublicKey) error {
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: RS256 requires an *rsa.PublicKey, got %T", ErrInvalidKey, key)
+	}
 
-	return err == nil
+	// For JWT verification, we need to hash the data first
+	hashed := sha256.Sum256(data)
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature); err != nil {
+		return ErrSignatureInvalid
+	}
+	return nil
 }
 
 // Name returns the name of the hashing algorithm
 func (h *RS256Hasher) Name() string {
 	return "RS256"
 }
+
+// rsaSigner checks that key can produce RSA signatures for alg
+func rsaSigner(alg string, key crypto.PrivateKey) (crypto.Signer, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s requires an *rsa.PrivateKey or crypto.Signer, got %T", ErrInvalidKey, alg, key)
+	}
+	if _, ok := signer.Public().(*rsa.PublicKey); !ok {
+		return nil, fmt.Errorf("%w: %s requires an RSA key, got a %T", ErrInvalidKey, alg, signer.Public())
+	}
+	return signer, nil
+}
+
+// signerError reports a failed signature. Local keys can only fail because
+// they are unusable; errors from external signers are passed through.
+func signerError(alg string, signer crypto.Signer, err error) error {
+	switch signer.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	default:
+		return fmt.Errorf("%s signer failed: %w", alg, err)
+	}
+}
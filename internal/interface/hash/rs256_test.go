@@ -5,6 +5,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"testing"
 )
 
@@ -25,10 +26,13 @@ func TestRS256Hasher_Sign(t *testing.T) {
 	data := []byte("Hello, World!")
 
 	// Sign the data
-	signature := hasher.Sign(data, privateKeyPEM)
+	signature, err := hasher.Sign(data, privateKeyPEM)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
 
 	// Check if the signature is not empty
-	if signature == "" {
+	if len(signature) == 0 {
 		t.Error("Expected non-empty signature, got empty")
 	}
 }
@@ -53,14 +57,28 @@ func TestRS256Hasher_Verify(t *testing.T) {
 	data := []byte("Hello, World!")
 
 	// Sign the data
-	signature := hasher.Sign(data, privateKeyPEM)
+	signature, err := hasher.Sign(data, privateKeyPEM)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
 
 	// Verify the signature
-	isValid := hasher.Verify(data, signature, publicKeyPEM)
+	if err := hasher.Verify(data, signature, publicKeyPEM); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
+	}
 
-	// Check if the verification is successful
-	if !isValid {
-		t.Error("Expected valid signature, got invalid")
+	// A tampered signature is reported as invalid, not as a key problem
+	signature[0] ^= 0xff
+	if err := hasher.Verify(data, signature, publicKeyPEM); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+	}
+
+	// An unparseable key is reported as ErrInvalidKey
+	if err := hasher.Verify(data, signature, []byte("not a key")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := hasher.Sign(data, []byte("not a key")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey from Sign, got %v", err)
 	}
 }
 
@@ -86,10 +104,13 @@ func TestRS256Hasher_Interface(t *testing.T) {
 	data := []byte("Hello, World!")
 
 	// Sign the data
-	signature := hasher.Sign(data, privateKeyPEM)
+	signature, err := hasher.Sign(data, privateKeyPEM)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
 
 	// Check if the signature is not empty
-	if signature == "" {
+	if len(signature) == 0 {
 		t.Error("Expected non-empty signature, got empty")
 	}
 
@@ -97,10 +118,7 @@ func TestRS256Hasher_Interface(t *testing.T) {
 	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&privateKey.PublicKey)})
 
 	// Verify the signature
-	isValid := hasher.Verify(data, signature, publicKeyPEM)
-
-	// Check if the verification is successful
-	if !isValid {
-		t.Error("Expected valid signature, got invalid")
+	if err := hasher.Verify(data, signature, publicKeyPEM); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
 	}
 }
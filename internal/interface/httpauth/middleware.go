@@ -0,0 +1,269 @@
+// Package httpauth authenticates net/http requests with RFC 6750 bearer
+// tokens
+package httpauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"jwt/internal/domain/jwt"
+)
+
+// Errors reported by extractors
+var (
+	// ErrNoToken is returned when a request carries no bearer token
+	ErrNoToken = errors.New("no bearer token")
+	// ErrMalformedRequest is returned for unparseable or ambiguous credentials
+	ErrMalformedRequest = errors.New("malformed bearer token request")
+)
+
+// Extractor finds the bearer token in a request. It returns ErrNoToken when
+// the request does not use its method.
+type Extractor func(r *http.Request) (string, error)
+
+// FromHeader extracts the token from "Authorization: Bearer <token>"
+// (RFC 6750 section 2.1)
+func FromHeader() Extractor {
+	return func(r *http.Request) (string, error) {
+		values := r.Header.Values("Authorization")
+		if len(values) == 0 {
+			return "", ErrNoToken
+		}
+		if len(values) > 1 {
+			return "", fmt.Errorf("%w: multiple Authorization headers", ErrMalformedRequest)
+		}
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return "", ErrNoToken
+		}
+		token = strings.TrimSpace(token)
+		if !ok || token == "" {
+			return "", fmt.Errorf("%w: empty bearer token", ErrMalformedRequest)
+		}
+		return token, nil
+	}
+}
+
+// FromCookie extracts the token from the named cookie
+func FromCookie(name string) Extractor {
+	return func(r *http.Request) (string, error) {
+		cookie, err := r.Cookie(name)
+		if err != nil || cookie.Value == "" {
+			return "", ErrNoToken
+		}
+		return cookie.Value, nil
+	}
+}
+
+// FromQuery extracts the token from a URI query parameter; RFC 6750 section
+// 2.3 names it access_token
+func FromQuery(param string) Extractor {
+	return func(r *http.Request) (string, error) {
+		values, ok := r.URL.Query()[param]
+		if !ok {
+			return "", ErrNoToken
+		}
+		if len(values) != 1 || values[0] == "" {
+			return "", fmt.Errorf("%w: expected a single %s parameter", ErrMalformedRequest, param)
+		}
+		return values[0], nil
+	}
+}
+
+// Middleware authenticates requests with bearer tokens
+type Middleware struct {
+	verifier   jwt.Verifier
+	extractors []Extractor
+	realm      string
+	scopes     []string
+	errorLog   *log.Logger
+}
+
+// Option configures optional Middleware behaviour
+type Option func(*Middleware)
+
+// WithExtractors sets where tokens are looked for. A request presenting a
+// token through more than one of them is rejected, as RFC 6750 requires.
+func WithExtractors(extractors ...Extractor) Option {
+	return func(m *Middleware) {
+		m.extractors = extractors
+	}
+}
+
+// WithRealm sets the realm reported in WWW-Authenticate challenges
+func WithRealm(realm string) Option {
+	return func(m *Middleware) {
+		m.realm = realm
+	}
+}
+
+// WithRequiredScopes requires every listed scope in the token's scope claim
+func WithRequiredScopes(scopes ...string) Option {
+	return func(m *Middleware) {
+		m.scopes = scopes
+	}
+}
+
+// WithErrorLog sets the logger receiving the details of rejected requests,
+// which clients only see as a fixed error_description. The log package's
+// standard logger is used by default.
+func WithErrorLog(logger *log.Logger) Option {
+	return func(m *Middleware) {
+		m.errorLog = logger
+	}
+}
+
+// New creates a bearer token middleware. By default only the Authorization
+// header is consulted.
+func New(verifier jwt.Verifier, opts ...Option) *Middleware {
+	m := &Middleware{
+		verifier:   verifier,
+		extractors: []Extractor{FromHeader()},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Handler wraps next, calling it only for requests with a valid token. The
+// verified token is available to next through TokenFromContext.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := m.extract(r)
+		switch {
+		case errors.Is(err, ErrNoToken):
+			m.challenge(w, http.StatusUnauthorized, "", "", "")
+			return
+		case err != nil:
+			m.logf("httpauth: rejected %s %s: %v", r.Method, r.URL.Path, err)
+			m.challenge(w, http.StatusBadRequest, "invalid_request", "malformed request", "")
+			return
+		}
+
+		token, err := m.verifier.Verify(raw)
+		if err != nil {
+			m.logf("httpauth: rejected token for %s %s: %v", r.Method, r.URL.Path, err)
+			m.challenge(w, http.StatusUnauthorized, "invalid_token", jwt.Describe(err), "")
+			return
+		}
+
+		if missing := missingScopes(token.Claims, m.scopes); len(missing) > 0 {
+			m.challenge(w, http.StatusForbidden, "insufficient_scope",
+				"the token lacks required scope", strings.Join(m.scopes, " "))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), token)))
+	})
+}
+
+// extract runs every extractor and insists on exactly one token
+func (m *Middleware) extract(r *http.Request) (string, error) {
+	var token string
+	for _, extractor := range m.extractors {
+		t, err := extractor(r)
+		if errors.Is(err, ErrNoToken) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if token != "" {
+			return "", fmt.Errorf("%w: more than one token presented", ErrMalformedRequest)
+		}
+		token = t
+	}
+	if token == "" {
+		return "", ErrNoToken
+	}
+	return token, nil
+}
+
+// logf reports a rejected request to the error log
+func (m *Middleware) logf(format string, args ...any) {
+	if m.errorLog != nil {
+		m.errorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// challenge writes an RFC 6750 section 3 error response
+func (m *Middleware) challenge(w http.ResponseWriter, status int, code, description, scope string) {
+	var params []string
+	if m.realm != "" {
+		params = append(params, fmt.Sprintf("realm=%s", quote(m.realm)))
+	}
+	if code != "" {
+		params = append(params, fmt.Sprintf("error=%s", quote(code)))
+	}
+	if description != "" {
+		params = append(params, fmt.Sprintf("error_description=%s", quote(description)))
+	}
+	if scope != "" {
+		params = append(params, fmt.Sprintf("scope=%s", quote(scope)))
+	}
+
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// quote renders an RFC 7230 quoted-string
+func quote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", " ", "\n", " ").Replace(s)
+	return `"` + s + `"`
+}
+
+// missingScopes returns the required scopes absent from the scope claim,
+// which may be a space-delimited string (RFC 8693) or an array
+func missingScopes(claims map[string]any, required []string) []string {
+	granted := map[string]bool{}
+	switch scope := claims["scope"].(type) {
+	case string:
+		for _, s := range strings.Fields(scope) {
+			granted[s] = true
+		}
+	case []any:
+		for _, s := range scope {
+			if s, ok := s.(string); ok {
+				granted[s] = true
+			}
+		}
+	}
+
+	var missing []string
+	for _, s := range required {
+		if !granted[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// NewContext returns a context carrying the verified token
+func NewContext(ctx context.Context, token *jwt.Token) context.Context {
+	return jwt.NewContext(ctx, token)
+}
+
+// TokenFromContext returns the verified token stored by the middleware
+func TokenFromContext(ctx context.Context) (*jwt.Token, bool) {
+	return jwt.FromContext(ctx)
+}
+
+// ClaimsFromContext returns the verified claims stored by the middleware
+func ClaimsFromContext(ctx context.Context) (map[string]any, bool) {
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return token.Claims, true
+}
@@ -0,0 +1,159 @@
+package httpauth_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/httpauth"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func TestMiddleware(t *testing.T) {
+	hasher, _ := hash.NewHasher(hash.HS256)
+	secret := []byte("middleware-secret-with-32-bytes!!")
+	encoder := jwtusecase.NewEncoder(hasher, secret)
+	verifier := jwtusecase.NewVerifier(hasher,
+		jwtusecase.WithKey(secret),
+		jwtusecase.WithPolicy(jwt.Policy{Audience: "api"}),
+	)
+
+	valid, _ := encoder.Encode(map[string]any{"sub": "alice", "aud": "api", "scope": "read write"})
+	readOnly, _ := encoder.Encode(map[string]any{"sub": "bob", "aud": "api", "scope": "read"})
+	expired, _ := encoder.Encode(map[string]any{"sub": "alice", "aud": "api", "exp": time.Now().Add(-time.Hour).Unix()})
+	otherAudience, _ := encoder.Encode(map[string]any{"sub": "alice", "aud": "admin"})
+	tampered := valid[:len(valid)-4] + "AAAA"
+
+	var errorLog bytes.Buffer
+
+	middleware := httpauth.New(verifier,
+		httpauth.WithExtractors(httpauth.FromHeader(), httpauth.FromCookie("session"), httpauth.FromQuery("access_token")),
+		httpauth.WithRealm("example"),
+		httpauth.WithRequiredScopes("write"),
+		httpauth.WithErrorLog(log.New(&errorLog, "", 0)),
+	)
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := httpauth.ClaimsFromContext(r.Context())
+		if !ok {
+			t.Error("Claims missing from request context")
+		}
+		w.Write([]byte(claims["sub"].(string)))
+	}))
+
+	tests := []struct {
+		name          string
+		prepare       func(r *http.Request)
+		wantStatus    int
+		wantBody      string
+		wantChallenge string
+		wantLog       string
+	}{
+		{
+			name:       "Authorization header",
+			prepare:    func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+valid) },
+			wantStatus: http.StatusOK,
+			wantBody:   "alice",
+		},
+		{
+			name:       "Lowercase scheme",
+			prepare:    func(r *http.Request) { r.Header.Set("Authorization", "bearer "+valid) },
+			wantStatus: http.StatusOK,
+			wantBody:   "alice",
+		},
+		{
+			name:       "Cookie",
+			prepare:    func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "session", Value: valid}) },
+			wantStatus: http.StatusOK,
+			wantBody:   "alice",
+		},
+		{
+			name:       "Query parameter",
+			prepare:    func(r *http.Request) { r.URL.RawQuery = "access_token=" + valid },
+			wantStatus: http.StatusOK,
+			wantBody:   "alice",
+		},
+		{
+			name:          "No token",
+			prepare:       func(r *http.Request) {},
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="example"`,
+		},
+		{
+			name:          "Basic credentials are not a bearer token",
+			prepare:       func(r *http.Request) { r.SetBasicAuth("alice", "password") },
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="example"`,
+		},
+		{
+			name:          "Expired token",
+			prepare:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+expired) },
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="example", error="invalid_token", error_description="token expired"`,
+		},
+		{
+			name:          "Tampered signature",
+			prepare:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+tampered) },
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `error="invalid_token", error_description="invalid signature"`,
+		},
+		{
+			name:          "Malformed token",
+			prepare:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer not-a-jwt") },
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `error="invalid_token", error_description="malformed token"`,
+		},
+		{
+			name:          "Other claim failures are not detailed",
+			prepare:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+otherAudience) },
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `error="invalid_token", error_description="invalid token"`,
+			wantLog:       "invalid audience: admin",
+		},
+		{
+			name: "Two methods at once",
+			prepare: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+valid)
+				r.URL.RawQuery = "access_token=" + valid
+			},
+			wantStatus:    http.StatusBadRequest,
+			wantChallenge: `error="invalid_request", error_description="malformed request"`,
+			wantLog:       "more than one token presented",
+		},
+		{
+			name:          "Insufficient scope",
+			prepare:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+readOnly) },
+			wantStatus:    http.StatusForbidden,
+			wantChallenge: `error="insufficient_scope", error_description="the token lacks required scope", scope="write"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errorLog.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			tt.prepare(req)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, rec.Body.String())
+			}
+			if challenge := rec.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, tt.wantChallenge) {
+				t.Errorf("Expected WWW-Authenticate to contain %q, got %q", tt.wantChallenge, challenge)
+			}
+			if !strings.Contains(errorLog.String(), tt.wantLog) {
+				t.Errorf("Expected the error log to contain %q, got %q", tt.wantLog, errorLog.String())
+			}
+		})
+	}
+}
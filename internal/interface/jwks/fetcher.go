@@ -0,0 +1,212 @@
+package jwks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"jwt/internal/domain/key"
+)
+
+// Defaults for Fetcher
+const (
+	// DefaultTTL is how long a fetched key set is cached
+	DefaultTTL = 10 * time.Minute
+	// DefaultMinRefresh limits refetches triggered by unknown kids
+	DefaultMinRefresh = 30 * time.Second
+	// maxBodySize bounds the size of a JWKS response
+	maxBodySize = 1 << 20
+)
+
+// Fetcher retrieves a JWKS over HTTP and caches it. It implements
+// jwt.KeySource, selecting keys by the token's kid header.
+type Fetcher struct {
+	url        string
+	client     *http.Client
+	ttl        time.Duration
+	minRefresh time.Duration
+	now        func() time.Time
+	// kind names the fetched document in errors and parse decodes it
+	kind  string
+	parse func([]byte) (*key.JWKS, error)
+
+	mu      sync.Mutex
+	set     *key.JWKS
+	fetched time.Time
+}
+
+// Option configures optional Fetcher behaviour
+type Option func(*Fetcher)
+
+// WithHTTPClient sets the client used to fetch the key set
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *Fetcher) {
+		f.client = client
+	}
+}
+
+// WithTTL sets how long a fetched key set is cached
+func WithTTL(ttl time.Duration) Option {
+	return func(f *Fetcher) {
+		f.ttl = ttl
+	}
+}
+
+// WithMinRefresh sets the minimum interval between refetches caused by a kid
+// missing from the cached set
+func WithMinRefresh(interval time.Duration) Option {
+	return func(f *Fetcher) {
+		f.minRefresh = interval
+	}
+}
+
+// WithClock sets the time source used for cache expiry
+func WithClock(now func() time.Time) Option {
+	return func(f *Fetcher) {
+		f.now = now
+	}
+}
+
+// NewFetcher creates a fetcher for the JWKS at url
+func NewFetcher(url string, opts ...Option) *Fetcher {
+	f := &Fetcher{
+		url:        url,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		ttl:        DefaultTTL,
+		minRefresh: DefaultMinRefresh,
+		now:        time.Now,
+		kind:       "JWKS",
+		parse:      key.ParseJWKS,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// JWKS returns the cached key set, fetching it when missing or expired
+func (f *Fetcher) JWKS() (*key.JWKS, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.set != nil && f.now().Sub(f.fetched) < f.ttl {
+		return f.set, nil
+	}
+	return f.refresh()
+}
+
+// Lookup returns the key with the given kid, refetching the set once when
+// the kid is unknown so newly rotated keys are picked up
+func (f *Fetcher) Lookup(kid string) (*key.JWK, error) {
+	set, err := f.JWKS()
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := set.Lookup(kid)
+	if !errors.Is(err, key.ErrKeyNotFound) {
+		return jwk, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.now().Sub(f.fetched) < f.minRefresh {
+		return nil, err
+	}
+	if set, err = f.refresh(); err != nil {
+		return nil, err
+	}
+	return set.Lookup(kid)
+}
+
+// VerificationKeys returns the JWK matching the header's kid, or every
+// signing key in the set when the token has no kid. Only keys suited to the
+// header's alg are returned.
+func (f *Fetcher) VerificationKeys(header map[string]any) ([][]byte, error) {
+	kid, _ := header["kid"].(string)
+	if kid != "" {
+		jwk, err := f.Lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		return encodeKeys(header, []key.JWK{*jwk})
+	}
+
+	set, err := f.JWKS()
+	if err != nil {
+		return nil, err
+	}
+	var candidates []key.JWK
+	for _, jwk := range set.Keys {
+		if jwk.Use == "" || jwk.Use == "sig" {
+			candidates = append(candidates, jwk)
+		}
+	}
+	return encodeKeys(header, candidates)
+}
+
+// refresh fetches the key set; the caller must hold f.mu
+func (f *Fetcher) refresh() (*key.JWKS, error) {
+	resp, err := f.client.Get(f.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", f.kind, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s returned %s", f.kind, f.url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", f.kind, err)
+	}
+	set, err := f.parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	f.set = set
+	f.fetched = f.now()
+	return set, nil
+}
+
+// encodeKeys converts the JWKs suited to the header's alg into key material
+// for the hashers: the raw secret for symmetric keys and the JWK JSON
+// otherwise. Every key source in this package goes through it, so a public
+// key is never handed to an HMAC hasher as a secret.
+func encodeKeys(header map[string]any, jwks []key.JWK) ([][]byte, error) {
+	alg, _ := header["alg"].(string)
+	if alg == "" {
+		return nil, fmt.Errorf("%w: the token has no alg header", key.ErrAlgorithmMismatch)
+	}
+	keys := make([][]byte, 0, len(jwks))
+	var mismatch error
+	for _, jwk := range jwks {
+		if err := jwk.CheckAlgorithm(alg); err != nil {
+			if mismatch == nil {
+				mismatch = err
+			}
+			continue
+		}
+		if jwk.Kty == "oct" {
+			secret, err := jwk.Key()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, secret.([]byte))
+			continue
+		}
+		data, err := json.Marshal(jwk)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, data)
+	}
+	if len(keys) == 0 && mismatch != nil {
+		return nil, mismatch
+	}
+	return keys, nil
+}
@@ -0,0 +1,155 @@
+package jwks_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/key"
+	"jwt/internal/interface/jwks"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func newJWK(t *testing.T, kid string) key.JWK {
+	t.Helper()
+	privateKey, err := key.Generate(key.EC, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, _ := key.Public(privateKey)
+	jwk, err := key.NewJWK(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk.Kid = kid
+	return *jwk
+}
+
+func TestFetcher_CachesAndRefreshesOnUnknownKid(t *testing.T) {
+	var requests atomic.Int32
+	set := key.JWKS{Keys: []key.JWK{newJWK(t, "a")}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode(set)
+	}))
+	defer ts.Close()
+
+	now := time.Unix(1700000000, 0)
+	fetcher := jwks.NewFetcher(ts.URL, jwks.WithClock(func() time.Time { return now }))
+
+	for i := 0; i < 3; i++ {
+		keys, err := fetcher.VerificationKeys(map[string]any{"alg": "ES256", "kid": "a"})
+		if err != nil || len(keys) != 1 {
+			t.Fatalf("Expected one key, got %d (%v)", len(keys), err)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected the set to be fetched once, got %d requests", got)
+	}
+
+	// A rotated key is picked up once the minimum refresh interval passed
+	set.Keys = append(set.Keys, newJWK(t, "b"))
+	if _, err := fetcher.VerificationKeys(map[string]any{"alg": "ES256", "kid": "b"}); !errors.Is(err, key.ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound within the refresh interval, got %v", err)
+	}
+	now = now.Add(jwks.DefaultMinRefresh)
+	if _, err := fetcher.VerificationKeys(map[string]any{"alg": "ES256", "kid": "b"}); err != nil {
+		t.Errorf("Expected rotated key to be found, got %v", err)
+	}
+
+	// Without a kid every signing key is a candidate
+	keys, err := fetcher.VerificationKeys(map[string]any{"alg": "ES256"})
+	if err != nil || len(keys) != 2 {
+		t.Errorf("Expected two candidate keys, got %d (%v)", len(keys), err)
+	}
+
+	// The cache expires after the TTL
+	before := requests.Load()
+	now = now.Add(jwks.DefaultTTL)
+	if _, err := fetcher.JWKS(); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != before+1 {
+		t.Error("Expected the set to be refetched after the TTL")
+	}
+}
+
+func TestFetcher_Errors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"not":"a jwks"}`))
+	}))
+	defer ts.Close()
+
+	if _, err := jwks.NewFetcher(ts.URL + "/missing").JWKS(); err == nil {
+		t.Error("Expected error for 404 response")
+	}
+	if _, err := jwks.NewFetcher(ts.URL + "/invalid").JWKS(); err == nil {
+		t.Error("Expected error for invalid JWKS")
+	}
+}
+
+func TestFetcher_SelectsKeysByAlgorithm(t *testing.T) {
+	rsaKey, err := key.Generate(key.RSA, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPublic, _ := key.Public(rsaKey)
+	rsaJWK, _ := key.NewJWK(rsaPublic)
+	rsaJWK.Kid = "rsa"
+	octJWK, _ := key.NewJWK([]byte(strings.Repeat("s", 32)))
+	octJWK.Kid = "oct"
+	pinned := newJWK(t, "pinned")
+	pinned.Alg = "ES384"
+	set := key.JWKS{Keys: []key.JWK{*rsaJWK, newJWK(t, "ec"), *octJWK, pinned}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(set)
+	}))
+	defer ts.Close()
+	fetcher := jwks.NewFetcher(ts.URL)
+
+	tests := []struct {
+		name     string
+		header   map[string]any
+		wantKeys int
+		wantErr  error
+	}{
+		{"HMAC gets only oct keys", map[string]any{"alg": "HS256"}, 1, nil},
+		{"RSA gets only RSA keys", map[string]any{"alg": "PS256"}, 1, nil},
+		{"ES256 skips the key pinned to ES384", map[string]any{"alg": "ES256"}, 1, nil},
+		{"Public key selected for HMAC", map[string]any{"alg": "HS256", "kid": "rsa"}, 0, key.ErrAlgorithmMismatch},
+		{"Pinned alg differs", map[string]any{"alg": "ES256", "kid": "pinned"}, 0, key.ErrAlgorithmMismatch},
+		{"No alg", map[string]any{"kid": "rsa"}, 0, key.ErrAlgorithmMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := fetcher.VerificationKeys(tt.header)
+			if !errors.Is(err, tt.wantErr) || len(keys) != tt.wantKeys {
+				t.Errorf("Expected %d keys and %v, got %d keys and %v", tt.wantKeys, tt.wantErr, len(keys), err)
+			}
+		})
+	}
+
+	// A token HMAC-signed with the published RSA key as the secret is rejected
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicJSON, _ := json.Marshal(rsaJWK)
+	forged, err := jwtusecase.NewEncoder(hasher, publicJSON, jwtusecase.WithKeyID("rsa")).Encode(map[string]any{"sub": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithKeySource(fetcher)).Verify(forged); !errors.Is(err, key.ErrAlgorithmMismatch) {
+		t.Errorf("Expected the forged token to be rejected with ErrAlgorithmMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package jwks
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"jwt/internal/domain/key"
+)
+
+const (
+	// maxHeaderURLs bounds how many distinct jku and x5u URLs are cached
+	maxHeaderURLs = 64
+	// maxRedirects matches the limit of the default http.Client
+	maxRedirects = 10
+)
+
+// ErrUntrustedURL is returned for jku and x5u URLs outside the allowlist
+var ErrUntrustedURL = errors.New("untrusted key URL")
+
+// HeaderURLs is a jwt.KeySource that fetches verification keys from the URL
+// in a token's jku header (a JWKS) or x5u header (a PEM certificate chain).
+// Only URLs under a trusted prefix are fetched, so a token cannot point the
+// verifier at keys of its own choosing; redirects must stay under a trusted
+// prefix too. Each URL gets its own cached Fetcher.
+type HeaderURLs struct {
+	trusted []*url.URL
+	opts    []Option
+
+	mu       sync.Mutex
+	fetchers map[string]*Fetcher
+}
+
+// NewHeaderURLs creates a key source trusting URLs under the given prefixes.
+// A prefix matches URLs with the same scheme and host whose path starts with
+// the prefix's path at a segment boundary, so "https://idp.example.com/keys"
+// trusts ".../keys/a.json" but neither ".../keys-old" nor
+// "https://idp.example.com.evil.test". The options configure each Fetcher.
+func NewHeaderURLs(prefixes []string, opts ...Option) (*HeaderURLs, error) {
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("at least one trusted key URL prefix is required")
+	}
+	trusted := make([]*url.URL, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		u, err := url.Parse(prefix)
+		if err != nil || !u.IsAbs() || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("invalid trusted key URL prefix %q: expected scheme://host[/path]", prefix)
+		}
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		trusted = append(trusted, u)
+	}
+	return &HeaderURLs{trusted: trusted, opts: opts, fetchers: make(map[string]*Fetcher)}, nil
+}
+
+// VerificationKeys fetches the keys named by the header's jku, or failing
+// that its x5u. For jku the key is selected by kid like Fetcher does. Either
+// way only keys suited to the header's alg are returned.
+func (s *HeaderURLs) VerificationKeys(header map[string]any) ([][]byte, error) {
+	if raw, ok := header["jku"]; ok {
+		jku, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("jku header must be a string")
+		}
+		fetcher, err := s.fetcher("jku", jku)
+		if err != nil {
+			return nil, err
+		}
+		return fetcher.VerificationKeys(header)
+	}
+
+	if raw, ok := header["x5u"]; ok {
+		x5u, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("x5u header must be a string")
+		}
+		fetcher, err := s.fetcher("x5u", x5u)
+		if err != nil {
+			return nil, err
+		}
+		set, err := fetcher.JWKS()
+		if err != nil {
+			return nil, err
+		}
+		return encodeKeys(header, set.Keys)
+	}
+
+	return nil, fmt.Errorf("token has no jku or x5u header")
+}
+
+// fetcher returns the cached fetcher for a trusted URL, creating it on
+// first use
+func (s *HeaderURLs) fetcher(param, raw string) (*Fetcher, error) {
+	if err := s.check(raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", param, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cacheKey := param + " " + raw
+	if f, ok := s.fetchers[cacheKey]; ok {
+		return f, nil
+	}
+	if len(s.fetchers) >= maxHeaderURLs {
+		for k := range s.fetchers {
+			delete(s.fetchers, k)
+			break
+		}
+	}
+	f := NewFetcher(raw, s.opts...)
+	f.client = s.guard(f.client)
+	if param == "x5u" {
+		f.kind = "certificate"
+		f.parse = parseCertificate
+	}
+	s.fetchers[cacheKey] = f
+	return f, nil
+}
+
+// guard returns a copy of client that only follows redirects to trusted URLs
+func (s *HeaderURLs) guard(client *http.Client) *http.Client {
+	guarded := *client
+	next := client.CheckRedirect
+	guarded.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := s.check(req.URL.String()); err != nil {
+			return fmt.Errorf("redirect: %w", err)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+	return &guarded
+}
+
+// check reports whether raw is under one of the trusted prefixes. URLs with
+// credentials, fragments or dot segments are never trusted.
+func (s *HeaderURLs) check(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.User != nil || u.Fragment != "" {
+		return fmt.Errorf("%w: %q", ErrUntrustedURL, raw)
+	}
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrUntrustedURL, raw)
+		}
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	for _, prefix := range s.trusted {
+		if strings.EqualFold(u.Scheme, prefix.Scheme) && strings.EqualFold(u.Host, prefix.Host) && hasPathPrefix(path, prefix.Path) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrUntrustedURL, raw)
+}
+
+// hasPathPrefix reports whether path starts with prefix at a segment boundary
+func hasPathPrefix(path, prefix string) bool {
+	if strings.HasSuffix(prefix, "/") {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// parseCertificate reads the first certificate of a PEM chain, the one whose
+// key signed the token, as a single-key set
+func parseCertificate(data []byte) (*key.JWKS, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("invalid certificate chain: no PEM certificate found")
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate chain: %w", err)
+		}
+		jwk, err := key.NewJWK(cert.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		return &key.JWKS{Keys: []key.JWK{*jwk}}, nil
+	}
+}
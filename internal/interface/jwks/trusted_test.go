@@ -0,0 +1,197 @@
+package jwks_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/key"
+	"jwt/internal/interface/jwks"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func TestHeaderURLs(t *testing.T) {
+	signingKey, err := key.Generate(key.EC, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, _ := key.Public(signingKey)
+	jwk, _ := key.NewJWK(publicKey)
+	jwk.Kid = "partner-1"
+
+	ecKey := signingKey.(*ecdsa.PrivateKey)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "partner.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &ecKey.PublicKey, ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var untrustedRequests atomic.Int32
+	untrusted := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		untrustedRequests.Add(1)
+		json.NewEncoder(w).Encode(key.JWKS{Keys: []key.JWK{*jwk}})
+	}))
+	defer untrusted.Close()
+
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/keys/jwks.json", "/keys-old/jwks.json", "/admin/jwks.json":
+			json.NewEncoder(w).Encode(key.JWKS{Keys: []key.JWK{*jwk}})
+		case "/keys/cert.pem":
+			pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+		case "/keys/moved.json":
+			http.Redirect(w, r, "/keys/jwks.json", http.StatusFound)
+		case "/keys/escape.json":
+			http.Redirect(w, r, "/admin/jwks.json", http.StatusFound)
+		case "/keys/away.json":
+			http.Redirect(w, r, untrusted.URL+"/keys/jwks.json", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	source, err := jwks.NewHeaderURLs([]string{ts.URL + "/keys"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher, err := hash.NewHasher(hash.ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := jwtusecase.NewVerifier(hasher, jwtusecase.WithKeySource(source))
+
+	t.Run("Allowed jku", func(t *testing.T) {
+		encoder := jwtusecase.NewKeyEncoder(hasher.(hash.KeyHasher), signingKey,
+			jwtusecase.WithKeyID("partner-1"), jwtusecase.WithHeader("jku", ts.URL+"/keys/jwks.json"))
+		token, err := encoder.Encode(map[string]any{"sub": "partner"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := requests.Load()
+		for i := 0; i < 3; i++ {
+			if _, err := verifier.Verify(token); err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+		}
+		if got := requests.Load() - before; got != 1 {
+			t.Errorf("Expected the JWKS to be fetched once, got %d requests", got)
+		}
+	})
+
+	t.Run("Allowed x5u", func(t *testing.T) {
+		encoder := jwtusecase.NewKeyEncoder(hasher.(hash.KeyHasher), signingKey,
+			jwtusecase.WithHeader("x5u", ts.URL+"/keys/cert.pem"))
+		token, err := encoder.Encode(map[string]any{"sub": "partner"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := verifier.Verify(token); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+	})
+
+	t.Run("Disallowed URLs", func(t *testing.T) {
+		host := strings.TrimPrefix(ts.URL, "http://")
+		for _, url := range []string{
+			ts.URL + "/admin/jwks.json",
+			ts.URL + "/keys-old/jwks.json",
+			ts.URL + "/keys/../admin/jwks.json",
+			ts.URL + "/keys/%2e%2e/admin/jwks.json",
+			"https://" + host + "/keys/jwks.json",
+			"http://user@" + host + "/keys/jwks.json",
+			"http://" + strings.Replace(host, "127.0.0.1", "localhost", 1) + "/keys/jwks.json",
+			"/keys/jwks.json",
+		} {
+			for _, param := range []string{"jku", "x5u"} {
+				before := requests.Load()
+				_, err := source.VerificationKeys(map[string]any{"alg": "ES256", param: url})
+				if !errors.Is(err, jwks.ErrUntrustedURL) {
+					t.Errorf("Expected ErrUntrustedURL for %s %s, got %v", param, url, err)
+				}
+				if requests.Load() != before {
+					t.Errorf("Expected no request for untrusted %s %s", param, url)
+				}
+			}
+		}
+	})
+
+	t.Run("Redirects", func(t *testing.T) {
+		if _, err := source.VerificationKeys(map[string]any{"alg": "ES256", "jku": ts.URL + "/keys/moved.json"}); err != nil {
+			t.Errorf("Expected a redirect under the trusted prefix to be followed, got %v", err)
+		}
+		for _, path := range []string{"/keys/escape.json", "/keys/away.json"} {
+			_, err := source.VerificationKeys(map[string]any{"alg": "ES256", "jku": ts.URL + path})
+			if !errors.Is(err, jwks.ErrUntrustedURL) {
+				t.Errorf("Expected ErrUntrustedURL for a redirect from %s, got %v", path, err)
+			}
+		}
+		if untrustedRequests.Load() != 0 {
+			t.Error("Expected no request to the untrusted redirect target")
+		}
+	})
+
+	t.Run("Forged HMAC token", func(t *testing.T) {
+		// A token HMAC-signed with the published public key as the secret
+		hmacHasher, err := hash.NewHasher(hash.HS256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicJSON, _ := json.Marshal(jwk)
+		forged, err := jwtusecase.NewEncoder(hmacHasher, publicJSON, jwtusecase.WithKeyID("partner-1"),
+			jwtusecase.WithHeader("jku", ts.URL+"/keys/jwks.json")).Encode(map[string]any{"sub": "admin"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hmacVerifier := jwtusecase.NewVerifier(hmacHasher, jwtusecase.WithKeySource(source))
+		if _, err := hmacVerifier.Verify(forged); !errors.Is(err, key.ErrAlgorithmMismatch) {
+			t.Errorf("Expected the forged token to be rejected with ErrAlgorithmMismatch, got %v", err)
+		}
+	})
+
+	t.Run("Invalid headers", func(t *testing.T) {
+		for _, header := range []map[string]any{
+			{"alg": "ES256"},
+			{"alg": "ES256", "jku": 42},
+			{"alg": "ES256", "x5u": []any{ts.URL + "/keys/cert.pem"}},
+			{"alg": "HS256", "x5u": ts.URL + "/keys/cert.pem"},
+		} {
+			if _, err := source.VerificationKeys(header); err == nil {
+				t.Errorf("Expected error for header %v", header)
+			}
+		}
+	})
+}
+
+func TestNewHeaderURLs_InvalidPrefixes(t *testing.T) {
+	for _, prefixes := range [][]string{
+		nil,
+		{"idp.example.com/keys"},
+		{"/keys"},
+		{"https://user@idp.example.com"},
+		{"https://idp.example.com/keys?x=1"},
+	} {
+		if _, err := jwks.NewHeaderURLs(prefixes); err == nil {
+			t.Errorf("Expected error for prefixes %q", prefixes)
+		}
+	}
+}
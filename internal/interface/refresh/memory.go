@@ -0,0 +1,120 @@
+// Package refresh provides jwt.RefreshStore implementations
+package refresh
+
+import (
+	"sync"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+// MemoryStore keeps refresh token families in memory. A family is forgotten
+// once its newest token has expired.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	families  map[string]*family
+	lastSweep time.Time
+}
+
+// family is the state of a chain of rotated refresh tokens
+type family struct {
+	tokens    map[string]bool // jti -> already exchanged
+	revoked   bool
+	expiresAt time.Time
+}
+
+// Option configures optional store behaviour
+type Option func(*MemoryStore)
+
+// WithClock sets the time source used for expiry
+func WithClock(now func() time.Time) Option {
+	return func(s *MemoryStore) {
+		s.now = now
+	}
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore(opts ...Option) *MemoryStore {
+	s := &MemoryStore{
+		now:      time.Now,
+		families: make(map[string]*family),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add records a newly issued refresh token
+func (s *MemoryStore) Add(familyID, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict()
+
+	f, ok := s.families[familyID]
+	if !ok {
+		f = &family{tokens: make(map[string]bool)}
+		s.families[familyID] = f
+	}
+	if f.revoked {
+		return jwt.ErrRevoked
+	}
+	f.tokens[jti] = false
+	if expiresAt.After(f.expiresAt) {
+		f.expiresAt = expiresAt
+	}
+	return nil
+}
+
+// Use marks a refresh token as exchanged
+func (s *MemoryStore) Use(familyID, jti string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict()
+
+	f, ok := s.families[familyID]
+	if !ok {
+		return jwt.ErrUnknownRefresh
+	}
+	if f.revoked {
+		return jwt.ErrRevoked
+	}
+	used, ok := f.tokens[jti]
+	if !ok {
+		return jwt.ErrUnknownRefresh
+	}
+	if used {
+		return jwt.ErrRefreshReused
+	}
+	f.tokens[jti] = true
+	return nil
+}
+
+// RevokeFamily invalidates every token of the family
+func (s *MemoryStore) RevokeFamily(familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.families[familyID]; ok {
+		f.revoked = true
+	}
+	return nil
+}
+
+// sweepInterval bounds how often expired families are looked for
+const sweepInterval = time.Minute
+
+// evict forgets families whose tokens have all expired; the caller holds s.mu
+func (s *MemoryStore) evict() {
+	now := s.now()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for id, f := range s.families {
+		if !now.Before(f.expiresAt) {
+			delete(s.families, id)
+		}
+	}
+}
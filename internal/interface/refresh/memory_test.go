@@ -0,0 +1,71 @@
+package refresh_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/refresh"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := refresh.NewMemoryStore(refresh.WithClock(func() time.Time { return now }))
+
+	if err := store.Add("fam", "a", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Use("fam", "a"); err != nil {
+		t.Fatalf("First use failed: %v", err)
+	}
+	if err := store.Use("fam", "a"); !errors.Is(err, jwt.ErrRefreshReused) {
+		t.Errorf("Expected ErrRefreshReused, got %v", err)
+	}
+	if err := store.Use("fam", "unknown"); !errors.Is(err, jwt.ErrUnknownRefresh) {
+		t.Errorf("Expected ErrUnknownRefresh, got %v", err)
+	}
+
+	store.Add("fam", "b", now.Add(time.Hour))
+	store.RevokeFamily("fam")
+	if err := store.Use("fam", "b"); !errors.Is(err, jwt.ErrRevoked) {
+		t.Errorf("Expected ErrRevoked, got %v", err)
+	}
+	if err := store.Add("fam", "c", now.Add(time.Hour)); !errors.Is(err, jwt.ErrRevoked) {
+		t.Errorf("Expected revoked family to refuse new tokens, got %v", err)
+	}
+
+	store.Add("short", "x", now.Add(time.Minute))
+	now = now.Add(2 * time.Hour)
+	if err := store.Use("short", "x"); !errors.Is(err, jwt.ErrUnknownRefresh) {
+		t.Errorf("Expected expired family to be forgotten, got %v", err)
+	}
+}
+
+func TestMemoryStore_ConcurrentUse(t *testing.T) {
+	store := refresh.NewMemoryStore()
+	store.Add("fam", "a", time.Now().Add(time.Hour))
+
+	var wg sync.WaitGroup
+	results := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- store.Use("fam", "a")
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("Expected exactly one exchange to succeed, got %d", succeeded)
+	}
+}
@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileStore is a MemoryStore persisted to an append-only file, so used token
+// IDs survive restarts. Expired entries are dropped when the file is opened.
+type FileStore struct {
+	memory *MemoryStore
+	path   string
+	file   *os.File
+}
+
+// record is one line of the store file
+type record struct {
+	JTI string `json:"jti"`
+	Exp int64  `json:"exp"`
+}
+
+// OpenFileStore loads the store at path, creating it if needed
+func OpenFileStore(path string, opts ...Option) (*FileStore, error) {
+	s := &FileStore{memory: NewMemoryStore(opts...), path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay store: %w", err)
+	}
+	s.file = file
+	return s, nil
+}
+
+// Use records jti until expiresAt, returning jwt.ErrReplayed for a jti that
+// is still recorded. The entry is kept in memory only once it is synced to
+// the file, so a failed write does not burn the jti until the next restart.
+func (s *FileStore) Use(jti string, expiresAt time.Time) error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+	needed, err := s.memory.check(jti, expiresAt)
+	if err != nil || !needed {
+		return err
+	}
+	line, err := json.Marshal(record{JTI: jti, Exp: ceilUnix(expiresAt)})
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write replay store: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to write replay store: %w", err)
+	}
+	s.memory.record(jti, expiresAt)
+	return nil
+}
+
+// Len returns the number of unexpired entries
+func (s *FileStore) Len() int {
+	return s.memory.Len()
+}
+
+// Close closes the underlying file
+func (s *FileStore) Close() error {
+	return s.file.Close()
+}
+
+// load reads the existing entries, skipping expired ones
+func (s *FileStore) load() error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open replay store: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil || r.JTI == "" {
+			return fmt.Errorf("invalid replay store %s: malformed entry on line %d", s.path, line)
+		}
+		// A jti recorded twice keeps its first expiry
+		_ = s.memory.use(r.JTI, time.Unix(r.Exp, 0))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read replay store: %w", err)
+	}
+	return nil
+}
+
+// compact rewrites the file with only the unexpired entries
+func (s *FileStore) compact() error {
+	tmp := s.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to compact replay store: %w", err)
+	}
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, e := range s.memory.expiry {
+		if err := enc.Encode(record{JTI: e.jti, Exp: ceilUnix(e.expiresAt)}); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to compact replay store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to compact replay store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to compact replay store: %w", err)
+	}
+	return nil
+}
+
+// ceilUnix rounds t up to whole seconds so entries are never kept too briefly
+func ceilUnix(t time.Time) int64 {
+	return t.Add(time.Second - 1).Unix()
+}
@@ -0,0 +1,112 @@
+// Package replay provides jwt.ReplayStore implementations
+package replay
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+// MemoryStore keeps used token IDs in memory until they expire
+type MemoryStore struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]time.Time
+	expiry  expiryHeap
+}
+
+// Option configures optional store behaviour
+type Option func(*MemoryStore)
+
+// WithClock sets the time source used for expiry
+func WithClock(now func() time.Time) Option {
+	return func(s *MemoryStore) {
+		s.now = now
+	}
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore(opts ...Option) *MemoryStore {
+	s := &MemoryStore{
+		now:     time.Now,
+		entries: make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Use records jti until expiresAt, returning jwt.ErrReplayed for a jti that
+// is still recorded
+func (s *MemoryStore) Use(jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.use(jti, expiresAt)
+}
+
+// Len returns the number of unexpired entries
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(s.now())
+	return len(s.entries)
+}
+
+// use implements Use; the caller holds s.mu
+func (s *MemoryStore) use(jti string, expiresAt time.Time) error {
+	needed, err := s.check(jti, expiresAt)
+	if err != nil || !needed {
+		return err
+	}
+	s.record(jti, expiresAt)
+	return nil
+}
+
+// check returns jwt.ErrReplayed for a recorded jti and otherwise reports
+// whether it needs recording, which expired ones do not; the caller holds s.mu
+func (s *MemoryStore) check(jti string, expiresAt time.Time) (bool, error) {
+	now := s.now()
+	s.evict(now)
+	if _, ok := s.entries[jti]; ok {
+		return false, jwt.ErrReplayed
+	}
+	return expiresAt.After(now), nil
+}
+
+// record adds a checked jti; the caller holds s.mu
+func (s *MemoryStore) record(jti string, expiresAt time.Time) {
+	s.entries[jti] = expiresAt
+	heap.Push(&s.expiry, entry{jti: jti, expiresAt: expiresAt})
+}
+
+// evict drops the entries that expired before now
+func (s *MemoryStore) evict(now time.Time) {
+	for len(s.expiry) > 0 && !s.expiry[0].expiresAt.After(now) {
+		e := heap.Pop(&s.expiry).(entry)
+		delete(s.entries, e.jti)
+	}
+}
+
+// entry is a recorded jti and its expiry
+type entry struct {
+	jti       string
+	expiresAt time.Time
+}
+
+// expiryHeap orders entries by expiry, soonest first
+type expiryHeap []entry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x any)        { *h = append(*h, x.(entry)) }
+func (h *expiryHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
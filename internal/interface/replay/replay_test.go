@@ -0,0 +1,128 @@
+package replay_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/replay"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := replay.NewMemoryStore(replay.WithClock(func() time.Time { return now }))
+
+	if err := store.Use("a", now.Add(time.Minute)); err != nil {
+		t.Fatalf("First use failed: %v", err)
+	}
+	if err := store.Use("a", now.Add(time.Minute)); !errors.Is(err, jwt.ErrReplayed) {
+		t.Errorf("Expected ErrReplayed, got %v", err)
+	}
+	if err := store.Use("b", now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("Use of another jti failed: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if n := store.Len(); n != 1 {
+		t.Errorf("Expected expired entry to be evicted, %d entries left", n)
+	}
+	if err := store.Use("a", now.Add(time.Minute)); err != nil {
+		t.Errorf("Expected jti to be usable after expiry, got %v", err)
+	}
+	if err := store.Use("c", now.Add(-time.Second)); err != nil || store.Len() != 2 {
+		t.Errorf("Expected already expired jti to be ignored, got %v with %d entries", err, store.Len())
+	}
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	store := replay.NewMemoryStore()
+	expires := time.Now().Add(time.Hour)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if store.Use("one-time", expires) == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted != 1 {
+		t.Errorf("Expected exactly one accepted use, got %d", accepted)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	clock := replay.WithClock(func() time.Time { return now })
+	path := filepath.Join(t.TempDir(), "replay.jsonl")
+
+	store, err := replay.OpenFileStore(path, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Use("short", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Use("long", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	now = now.Add(10 * time.Minute)
+	store, err = replay.OpenFileStore(path, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if err := store.Use("long", now.Add(time.Hour)); !errors.Is(err, jwt.ErrReplayed) {
+		t.Errorf("Expected ErrReplayed after reopening, got %v", err)
+	}
+	if err := store.Use("short", now.Add(time.Hour)); err != nil {
+		t.Errorf("Expected expired entry to be dropped, got %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if want := "{\"jti\":\"long\",\"exp\":1700003600}\n{\"jti\":\"short\",\"exp\":1700004200}\n"; string(data) != want {
+		t.Errorf("Expected compacted file %q, got %q", want, data)
+	}
+}
+
+func TestFileStore_WriteFailure(t *testing.T) {
+	store, err := replay.OpenFileStore(filepath.Join(t.TempDir(), "replay.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Writes to the closed file fail
+	store.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := store.Use("a", time.Now().Add(time.Hour)); err == nil || errors.Is(err, jwt.ErrReplayed) {
+			t.Errorf("Attempt %d: expected a write error, got %v", i+1, err)
+		}
+	}
+	if store.Len() != 0 {
+		t.Errorf("Expected the unwritten jti not to be recorded, got %d entries", store.Len())
+	}
+}
+
+func TestOpenFileStore_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replay.jsonl")
+	os.WriteFile(path, []byte("{\"jti\":\"a\",\"exp\":1}\nnot json\n"), 0o600)
+
+	if _, err := replay.OpenFileStore(path); err == nil {
+		t.Error("Expected error for malformed store file")
+	}
+}
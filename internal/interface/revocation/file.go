@@ -0,0 +1,145 @@
+// Package revocation provides a file-backed jwt.RevocationChecker
+package revocation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+// DefaultReloadInterval limits how often the denylist file is checked for
+// changes
+const DefaultReloadInterval = time.Second
+
+// Load reads a denylist file. A missing file is an empty denylist.
+func Load(path string) (*jwt.Denylist, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &jwt.Denylist{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read denylist: %w", err)
+	}
+	var list jwt.Denylist
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("invalid denylist %s: %w", path, err)
+	}
+	return &list, nil
+}
+
+// Save writes the denylist atomically, so a FileChecker never reads a
+// partially written file
+func Save(path string, list *jwt.Denylist) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write denylist: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write denylist: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write denylist: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write denylist: %w", err)
+	}
+	return nil
+}
+
+// FileChecker checks tokens against a denylist file, reloading it whenever
+// the file changes
+type FileChecker struct {
+	path     string
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	list    *jwt.Denylist
+	modTime time.Time
+	size    int64
+	checked time.Time
+}
+
+// Option configures optional FileChecker behaviour
+type Option func(*FileChecker)
+
+// WithReloadInterval sets how often the file is checked for changes
+func WithReloadInterval(interval time.Duration) Option {
+	return func(c *FileChecker) {
+		c.interval = interval
+	}
+}
+
+// WithClock sets the time source used to schedule reloads
+func WithClock(now func() time.Time) Option {
+	return func(c *FileChecker) {
+		c.now = now
+	}
+}
+
+// NewFileChecker creates a checker for the denylist at path. The file is
+// read on first use and may not exist yet.
+func NewFileChecker(path string, opts ...Option) *FileChecker {
+	c := &FileChecker{
+		path:     path,
+		interval: DefaultReloadInterval,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// CheckRevoked matches the token against the current denylist. If the file
+// cannot be read every token is rejected rather than let revoked ones pass.
+func (c *FileChecker) CheckRevoked(token *jwt.Token) error {
+	list, err := c.current()
+	if err != nil {
+		return err
+	}
+	return list.CheckRevoked(token)
+}
+
+// current returns the denylist, reloading it if the file changed
+func (c *FileChecker) current() (*jwt.Denylist, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.list != nil && now.Sub(c.checked) < c.interval {
+		return c.list, nil
+	}
+
+	var modTime time.Time
+	var size int64
+	info, err := os.Stat(c.path)
+	switch {
+	case err == nil:
+		modTime, size = info.ModTime(), info.Size()
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read denylist: %w", err)
+	}
+
+	if c.list == nil || !modTime.Equal(c.modTime) || size != c.size {
+		list, err := Load(c.path)
+		if err != nil {
+			return nil, err
+		}
+		c.list, c.modTime, c.size = list, modTime, size
+	}
+	c.checked = now
+	return c.list, nil
+}
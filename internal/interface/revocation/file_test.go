@@ -0,0 +1,68 @@
+package revocation_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/revocation"
+)
+
+func TestFileChecker_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.json")
+	now := time.Unix(1700000000, 0)
+	checker := revocation.NewFileChecker(path,
+		revocation.WithReloadInterval(time.Second),
+		revocation.WithClock(func() time.Time { return now }),
+	)
+	token := &jwt.Token{Claims: map[string]any{"jti": "abc"}}
+
+	if err := checker.CheckRevoked(token); err != nil {
+		t.Fatalf("Expected missing file to revoke nothing, got %v", err)
+	}
+
+	list := &jwt.Denylist{}
+	list.RevokeJTI("abc")
+	if err := revocation.Save(path, list); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checker.CheckRevoked(token); err != nil {
+		t.Errorf("Expected file to be rechecked only after the interval, got %v", err)
+	}
+	now = now.Add(time.Second)
+	if err := checker.CheckRevoked(token); !errors.Is(err, jwt.ErrRevoked) {
+		t.Errorf("Expected ErrRevoked after reload, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Second)
+	other := &jwt.Token{Claims: map[string]any{"jti": "other"}}
+	if err := checker.CheckRevoked(other); err == nil {
+		t.Error("Expected an unreadable denylist to reject tokens")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.json")
+	list := &jwt.Denylist{}
+	list.RevokeJTI("abc")
+	list.RevokeSubject("user-1", time.Unix(1700000000, 0))
+	list.RevokeKey("k1")
+
+	if err := revocation.Save(path, list); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := revocation.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.JTIs) != 1 || loaded.Subjects["user-1"] != 1700000000 || len(loaded.KeyIDs) != 1 {
+		t.Errorf("Unexpected denylist after round trip: %+v", loaded)
+	}
+}
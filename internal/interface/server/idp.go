@@ -0,0 +1,205 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	"jwt/internal/domain/key"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+// IdentityProviderConfig configures a mock identity provider
+type IdentityProviderConfig struct {
+	// Issuer is the iss claim and the base URL of the discovery document
+	Issuer string
+	// Algorithm is the signing algorithm; it must use an asymmetric key
+	Algorithm hash.Algorithm
+	// SigningKey is the private key material (PEM or JWK)
+	SigningKey []byte
+	// TTL is the lifetime of issued tokens
+	TTL time.Duration
+	// Claims are added to every issued token; request claims override them
+	Claims map[string]any
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// IdentityProvider is a local token issuer exposing OIDC discovery and JWKS
+type IdentityProvider struct {
+	issuer    string
+	algorithm hash.Algorithm
+	encoder   jwt.Encoder
+	jwks      key.JWKS
+	ttl       time.Duration
+	claims    map[string]any
+	now       func() time.Time
+}
+
+// NewIdentityProvider creates a mock identity provider
+func NewIdentityProvider(cfg IdentityProviderConfig) (*IdentityProvider, error) {
+	if cfg.Issuer == "" {
+		return nil, fmt.Errorf("issuer is required")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	hasher, err := hash.NewHasher(cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := key.ParsePrivateKey(cfg.SigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	if _, ok := privateKey.([]byte); ok {
+		return nil, fmt.Errorf("invalid signing key: a JWKS cannot publish a symmetric key")
+	}
+	publicKey, err := key.Public(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := key.NewJWK(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if jwk.Kid, err = jwk.Thumbprint(); err != nil {
+		return nil, err
+	}
+	jwk.Use = "sig"
+	jwk.Alg = string(cfg.Algorithm)
+
+	// Fail fast when the key does not suit the algorithm
+	encoder := jwtusecase.NewEncoder(hasher, cfg.SigningKey, jwtusecase.WithKeyID(jwk.Kid))
+	if _, err := encoder.Encode(map[string]any{}); err != nil {
+		return nil, err
+	}
+
+	return &IdentityProvider{
+		issuer:    strings.TrimRight(cfg.Issuer, "/"),
+		algorithm: cfg.Algorithm,
+		encoder:   encoder,
+		jwks:      key.JWKS{Keys: []key.JWK{*jwk}},
+		ttl:       cfg.TTL,
+		claims:    cfg.Claims,
+		now:       cfg.Now,
+	}, nil
+}
+
+// Handler returns the HTTP routes of the identity provider
+func (p *IdentityProvider) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("GET /jwks.json", p.handleJWKS)
+	mux.HandleFunc("POST /token", p.handleToken)
+	return mux
+}
+
+// Issue signs a token with the default claims overridden by claims
+func (p *IdentityProvider) Issue(claims map[string]any) (string, error) {
+	now := p.now()
+	merged := map[string]any{
+		"iss": p.issuer,
+		"iat": now.Unix(),
+		"exp": now.Add(p.ttl).Unix(),
+		"jti": newID(),
+	}
+	for name, value := range p.claims {
+		merged[name] = value
+	}
+	for name, value := range claims {
+		merged[name] = value
+	}
+	return p.encoder.Encode(merged)
+}
+
+// handleDiscovery serves the OpenID Connect discovery document
+func (p *IdentityProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.issuer,
+		"jwks_uri":                              p.issuer + "/jwks.json",
+		"token_endpoint":                        p.issuer + "/token",
+		"grant_types_supported":                 []string{"client_credentials"},
+		"response_types_supported":              []string{"token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{string(p.algorithm)},
+		"token_endpoint_auth_methods_supported": []string{"none", "client_secret_post"},
+	})
+}
+
+// handleJWKS serves the public signing key
+func (p *IdentityProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, p.jwks)
+}
+
+// handleToken issues a token. A JSON body is used as the claims; a form body
+// maps client_id to sub, audience to aud and passes scope through.
+func (p *IdentityProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	claims := map[string]any{}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&claims); err != nil {
+			writeOAuthError(w, "invalid_request", "request body must be a JSON object of claims")
+			return
+		}
+	case "application/x-www-form-urlencoded", "":
+		if err := r.ParseForm(); err != nil {
+			writeOAuthError(w, "invalid_request", "malformed form body")
+			return
+		}
+		for field, claim := range map[string]string{"client_id": "sub", "audience": "aud", "scope": "scope"} {
+			if value := r.PostForm.Get(field); value != "" {
+				claims[claim] = value
+			}
+		}
+	default:
+		writeOAuthError(w, "invalid_request", "unsupported content type "+mediaType)
+		return
+	}
+
+	token, err := p.Issue(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error", "error_description": err.Error()})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int64(p.ttl / time.Second),
+	})
+}
+
+// writeOAuthError writes an RFC 6749 section 5.2 error response
+func writeOAuthError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// newID returns a random identifier for the jti claim
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
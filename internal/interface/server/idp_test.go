@@ -0,0 +1,173 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	"jwt/internal/domain/key"
+	"jwt/internal/interface/server"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func newTestIdentityProvider(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	privateKey, err := key.Generate(key.RSA, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePEM, _ := key.MarshalPrivateKeyPEM(privateKey)
+
+	ts := httptest.NewUnstartedServer(nil)
+	issuer := "http://" + ts.Listener.Addr().String()
+	idp, err := server.NewIdentityProvider(server.IdentityProviderConfig{
+		Issuer:     issuer,
+		Algorithm:  hash.RS256,
+		SigningKey: privatePEM,
+		TTL:        time.Minute,
+		Claims:     map[string]any{"aud": "api", "roles": []any{"admin"}},
+	})
+	if err != nil {
+		t.Fatalf("NewIdentityProvider returned error: %v", err)
+	}
+	ts.Config.Handler = idp.Handler()
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func getJSON(t *testing.T, url string, v any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: expected 200, got %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIdentityProvider_IssuedTokensVerifyAgainstJWKS(t *testing.T) {
+	ts := newTestIdentityProvider(t)
+
+	var discovery struct {
+		Issuer        string `json:"issuer"`
+		JWKSURI       string `json:"jwks_uri"`
+		TokenEndpoint string `json:"token_endpoint"`
+	}
+	getJSON(t, ts.URL+"/.well-known/openid-configuration", &discovery)
+	if discovery.Issuer != ts.URL || discovery.JWKSURI != ts.URL+"/jwks.json" {
+		t.Fatalf("Unexpected discovery document: %+v", discovery)
+	}
+
+	var jwks key.JWKS
+	getJSON(t, discovery.JWKSURI, &jwks)
+	if len(jwks.Keys) != 1 || jwks.Keys[0].IsPrivate() || jwks.Keys[0].Kid == "" {
+		t.Fatalf("Unexpected JWKS: %+v", jwks)
+	}
+	publicJWK, _ := json.Marshal(jwks.Keys[0])
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []string
+	}{
+		{
+			name:        "JSON claims",
+			contentType: "application/json",
+			body:        `{"sub":"alice","roles":["reader"]}`,
+			want:        []string{`"sub": "alice"`, `"reader"`, `"aud": "api"`},
+		},
+		{
+			name:        "Form client credentials",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"grant_type": {"client_credentials"}, "client_id": {"service-a"}, "scope": {"read"}}.Encode(),
+			want:        []string{`"sub": "service-a"`, `"scope": "read"`, `"admin"`},
+		},
+	}
+
+	hasher, _ := hash.NewHasher(hash.RS256)
+	decoder := jwtusecase.NewDecoder(hasher,
+		jwtusecase.WithKey(publicJWK),
+		jwtusecase.WithPolicy(jwt.Policy{Issuer: ts.URL, Audience: "api"}),
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(discovery.TokenEndpoint, tt.contentType, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected 200, got %d", resp.StatusCode)
+			}
+			var tokenResponse struct {
+				AccessToken string `json:"access_token"`
+				TokenType   string `json:"token_type"`
+				ExpiresIn   int    `json:"expires_in"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+				t.Fatal(err)
+			}
+			if tokenResponse.TokenType != "Bearer" || tokenResponse.ExpiresIn != 60 {
+				t.Errorf("Unexpected token response: %+v", tokenResponse)
+			}
+
+			output, err := decoder.Decode(tokenResponse.AccessToken, true)
+			if err != nil {
+				t.Fatalf("Issued token did not validate: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got %q", want, output)
+				}
+			}
+		})
+	}
+}
+
+func TestIdentityProvider_InvalidRequests(t *testing.T) {
+	ts := newTestIdentityProvider(t)
+
+	resp, err := http.Post(ts.URL+"/token", "application/json", strings.NewReader(`["not","claims"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for non-object claims, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET /token, got %d", resp.StatusCode)
+	}
+}
+
+func TestNewIdentityProvider_RejectsSymmetricKeys(t *testing.T) {
+	_, err := server.NewIdentityProvider(server.IdentityProviderConfig{
+		Issuer:     "http://localhost",
+		Algorithm:  hash.RS256,
+		SigningKey: []byte(`{"kty":"oct","k":"c2VjcmV0"}`),
+	})
+	if err == nil {
+		t.Error("Expected error for symmetric signing key")
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"jwt/internal/domain/jwt"
+)
+
+// IntrospectorConfig configures a token introspection endpoint
+type IntrospectorConfig struct {
+	// Verifier validates submitted tokens
+	Verifier jwt.Verifier
+	// ClientID and ClientSecret, when set, are required as HTTP Basic
+	// credentials from callers, as RFC 7662 section 2.1 recommends
+	ClientID     string
+	ClientSecret string
+}
+
+// Introspector serves RFC 7662 token introspection
+type Introspector struct {
+	verifier     jwt.Verifier
+	clientID     string
+	clientSecret string
+}
+
+// NewIntrospector creates a token introspection endpoint
+func NewIntrospector(cfg IntrospectorConfig) *Introspector {
+	return &Introspector{
+		verifier:     cfg.Verifier,
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+	}
+}
+
+// Handler returns the HTTP routes of the introspection endpoint
+func (i *Introspector) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /introspect", i.handleIntrospect)
+	return mux
+}
+
+// Introspect validates a token and builds the introspection response. Invalid
+// tokens yield only {"active": false}, revealing nothing about why.
+func (i *Introspector) Introspect(token string) map[string]any {
+	parsed, err := i.verifier.Verify(token)
+	if err != nil {
+		return map[string]any{"active": false}
+	}
+
+	response := make(map[string]any, len(parsed.Claims)+2)
+	for name, value := range parsed.Claims {
+		response[name] = value
+	}
+	if _, ok := response["token_type"]; !ok {
+		response["token_type"] = "Bearer"
+	}
+	response["active"] = true
+	return response
+}
+
+// handleIntrospect accepts the token as a form parameter (RFC 7662 section
+// 2.1) or, for convenience, as {"token": "..."} JSON
+func (i *Introspector) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	if !i.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="introspection"`)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	var token string
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var body struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&body); err != nil {
+			writeOAuthError(w, "invalid_request", "malformed JSON body")
+			return
+		}
+		token = body.Token
+	default:
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		if err := r.ParseForm(); err != nil {
+			writeOAuthError(w, "invalid_request", "malformed form body")
+			return
+		}
+		token = r.PostForm.Get("token")
+	}
+
+	if token == "" {
+		writeOAuthError(w, "invalid_request", "missing token parameter")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, i.Introspect(token))
+}
+
+// authorized checks the caller's Basic credentials when they are configured
+func (i *Introspector) authorized(r *http.Request) bool {
+	if i.clientID == "" && i.clientSecret == "" {
+		return true
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	idMatch := subtle.ConstantTimeCompare([]byte(id), []byte(i.clientID))
+	secretMatch := subtle.ConstantTimeCompare([]byte(secret), []byte(i.clientSecret))
+	return idMatch&secretMatch == 1
+}
@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/server"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func TestIntrospector(t *testing.T) {
+	hasher, _ := hash.NewHasher(hash.HS256)
+	secret := []byte("introspection-secret-with-32-bytes!")
+	verifier := jwtusecase.NewVerifier(hasher,
+		jwtusecase.WithKey(secret),
+		jwtusecase.WithPolicy(jwt.Policy{Audience: "api"}),
+	)
+	introspector := server.NewIntrospector(server.IntrospectorConfig{
+		Verifier:     verifier,
+		ClientID:     "gateway",
+		ClientSecret: "s3cret",
+	})
+	ts := httptest.NewServer(introspector.Handler())
+	defer ts.Close()
+
+	encoder := jwtusecase.NewEncoder(hasher, secret)
+	exp := time.Now().Add(time.Hour).Unix()
+	valid, _ := encoder.Encode(map[string]any{"sub": "alice", "aud": "api", "scope": "read write", "exp": exp})
+	wrongAudience, _ := encoder.Encode(map[string]any{"sub": "alice", "aud": "web"})
+
+	introspect := func(token string, auth bool) (*http.Response, map[string]any) {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/introspect", strings.NewReader(url.Values{"token": {token}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if auth {
+			req.SetBasicAuth("gateway", "s3cret")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp, body
+	}
+
+	resp, body := introspect(valid, true)
+	if resp.StatusCode != http.StatusOK || body["active"] != true {
+		t.Fatalf("Expected active token, got %d %v", resp.StatusCode, body)
+	}
+	if body["sub"] != "alice" || body["scope"] != "read write" || body["exp"] != float64(exp) || body["token_type"] != "Bearer" {
+		t.Errorf("Unexpected introspection response: %v", body)
+	}
+
+	for name, token := range map[string]string{"wrong audience": wrongAudience, "garbage": "not.a.jwt"} {
+		_, body := introspect(token, true)
+		if len(body) != 1 || body["active"] != false {
+			t.Errorf("%s: expected only active=false, got %v", name, body)
+		}
+	}
+
+	resp, _ = introspect(valid, false)
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected 401 with challenge without credentials, got %d", resp.StatusCode)
+	}
+
+	resp, _ = introspect("", true)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for missing token, got %d", resp.StatusCode)
+	}
+}
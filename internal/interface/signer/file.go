@@ -0,0 +1,137 @@
+// Package signer provides crypto.Signer backends for keys that must not be
+// exported, such as keys held by a KMS or HSM
+package signer
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"jwt/internal/domain/key"
+)
+
+// ErrKeyNotFound is returned when a provider has no key with the given ID
+var ErrKeyNotFound = errors.New("signing key not found")
+
+// FileProvider is a local stand-in for a KMS. Private keys are kept in a
+// directory, one PEM or JWK file per key ID, and handed out only as
+// crypto.Signers. Each signature re-reads the key file, so deleting a file
+// disables its key just as disabling a key in a KMS would. It is meant for
+// tests and development, not for protecting production keys.
+type FileProvider struct {
+	dir string
+
+	mu    sync.Mutex
+	signs map[string]int
+}
+
+// NewFileProvider creates a provider for the keys in dir
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir, signs: make(map[string]int)}
+}
+
+// CreateKey generates a key, stores it under keyID and returns its public
+// half. An existing key with the same ID is replaced.
+func (p *FileProvider) CreateKey(keyID string, keyType key.Type, size int) (crypto.PublicKey, error) {
+	path, err := p.path(keyID)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := key.Generate(keyType, size)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := privateKey.(crypto.Signer); !ok {
+		return nil, fmt.Errorf("%s keys cannot be used as signers", keyType)
+	}
+	data, err := key.MarshalPrivateKeyPEM(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to store key %s: %w", keyID, err)
+	}
+	return key.Public(privateKey)
+}
+
+// Signer returns a crypto.Signer for the key stored under keyID
+func (p *FileProvider) Signer(keyID string) (crypto.Signer, error) {
+	privateKey, err := p.load(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSigner{provider: p, keyID: keyID, public: privateKey.Public()}, nil
+}
+
+// SignCount returns how many signatures the key stored under keyID has made
+func (p *FileProvider) SignCount(keyID string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.signs[keyID]
+}
+
+// load reads and parses the key stored under keyID
+func (p *FileProvider) load(keyID string) (crypto.Signer, error) {
+	path, err := p.path(keyID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", keyID, err)
+	}
+	privateKey, err := key.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %s: %w", keyID, err)
+	}
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key %s cannot be used as a signer", keyID)
+	}
+	return signer, nil
+}
+
+// path maps a key ID to its file, rejecting IDs that would leave the
+// provider's directory
+func (p *FileProvider) path(keyID string) (string, error) {
+	if keyID == "" || keyID == "." || keyID == ".." || strings.ContainsAny(keyID, `/\`) {
+		return "", fmt.Errorf("invalid key ID %q", keyID)
+	}
+	return filepath.Join(p.dir, keyID+".pem"), nil
+}
+
+// fileSigner signs with a FileProvider key without holding the key itself
+type fileSigner struct {
+	provider *FileProvider
+	keyID    string
+	public   crypto.PublicKey
+}
+
+// Public returns the public half of the key
+func (s *fileSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign loads the key and signs digest with it
+func (s *fileSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	privateKey, err := s.provider.load(s.keyID)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := privateKey.Sign(rand, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.provider.mu.Lock()
+	s.provider.signs[s.keyID]++
+	s.provider.mu.Unlock()
+	return signature, nil
+}
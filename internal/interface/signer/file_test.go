@@ -0,0 +1,96 @@
+package signer_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/key"
+	"jwt/internal/interface/signer"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func rs256(t *testing.T) hash.KeyHasher {
+	t.Helper()
+	hasher, err := hash.NewHasher(hash.RS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hasher.(hash.KeyHasher)
+}
+
+func TestFileProvider_SignsTokens(t *testing.T) {
+	dir := t.TempDir()
+	provider := signer.NewFileProvider(dir)
+	publicKey, err := provider.CreateKey("signing-2024", key.RSA, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kms, err := provider.Signer("signing-2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasher := rs256(t)
+	encoder := jwtusecase.NewSignerEncoder(hasher, kms, jwtusecase.WithKeyID("signing-2024"))
+	token, err := encoder.Encode(map[string]any{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if got := provider.SignCount("signing-2024"); got != 1 {
+		t.Errorf("Expected 1 signature from the provider, got %d", got)
+	}
+
+	// The token verifies with the public key alone, in both PEM and parsed form
+	publicPEM, err := key.MarshalPublicKeyPEM(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(publicPEM)).Verify(token); err != nil {
+		t.Errorf("Verify with PEM key failed: %v", err)
+	}
+	if _, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithVerificationKey(kms.Public())).Verify(token); err != nil {
+		t.Errorf("Verify with parsed key failed: %v", err)
+	}
+
+	// Deleting the key file disables the key
+	if err := os.Remove(filepath.Join(dir, "signing-2024.pem")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encoder.Encode(map[string]any{"sub": "alice"}); !errors.Is(err, signer.ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound after deleting the key, got %v", err)
+	}
+}
+
+func TestFileProvider_Errors(t *testing.T) {
+	provider := signer.NewFileProvider(t.TempDir())
+
+	if _, err := provider.Signer("missing"); !errors.Is(err, signer.ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+	for _, id := range []string{"", "..", "../escape", `a\b`} {
+		if _, err := provider.CreateKey(id, key.RSA, 2048); err == nil {
+			t.Errorf("Expected error for key ID %q", id)
+		}
+	}
+	if _, err := provider.CreateKey("secret", key.Oct, 256); err == nil {
+		t.Error("Expected error creating a symmetric key")
+	}
+
+	// An EC key cannot produce RS256 signatures
+	if _, err := provider.CreateKey("ec", key.EC, 256); err != nil {
+		t.Fatal(err)
+	}
+	ec, err := provider.Signer("ec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwtusecase.NewSignerEncoder(rs256(t), ec).Encode(map[string]any{"sub": "alice"}); !errors.Is(err, hash.ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for an EC signer, got %v", err)
+	}
+	if got := provider.SignCount("ec"); got != 0 {
+		t.Errorf("Expected no signatures from a rejected signer, got %d", got)
+	}
+}
@@ -0,0 +1,42 @@
+package signer
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+)
+
+// ErrPKCS11Unavailable is returned by OpenPKCS11 in builds without PKCS#11
+// support
+var ErrPKCS11Unavailable = errors.New("PKCS#11 support is not compiled in; rebuild with -tags pkcs11 and cgo enabled")
+
+// Signer is a crypto.Signer holding resources, such as an HSM session, that
+// must be released with Close
+type Signer interface {
+	crypto.Signer
+	Close() error
+}
+
+// PKCS11Config selects a private key on a PKCS#11 token
+type PKCS11Config struct {
+	// Module is the path of the PKCS#11 library, e.g. libsofthsm2.so
+	Module string
+	// Slot is the ID of the slot holding the token
+	Slot uint
+	// KeyLabel is the CKA_LABEL of the private key; the public key must
+	// carry the same label
+	KeyLabel string
+	// PIN is the user PIN; empty skips login
+	PIN string
+}
+
+// validate checks that the required fields are set
+func (c PKCS11Config) validate() error {
+	if c.Module == "" {
+		return fmt.Errorf("a PKCS#11 module path is required")
+	}
+	if c.KeyLabel == "" {
+		return fmt.Errorf("a PKCS#11 key label is required")
+	}
+	return nil
+}
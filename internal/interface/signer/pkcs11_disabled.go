@@ -0,0 +1,11 @@
+//go:build !pkcs11 || !cgo
+
+package signer
+
+// OpenPKCS11 is unavailable in this build
+func OpenPKCS11(cfg PKCS11Config) (Signer, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return nil, ErrPKCS11Unavailable
+}
@@ -0,0 +1,302 @@
+//go:build pkcs11 && cgo
+
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// pkcs11Signer signs with a private key that never leaves the token. A
+// PKCS#11 session runs one operation at a time, so signing is serialized.
+type pkcs11Signer struct {
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	key     pkcs11.ObjectHandle
+	public  crypto.PublicKey
+	// owned is set when this signer initialized the module and so must
+	// finalize it; other users of the module in the process are unaffected
+	owned bool
+
+	mu sync.Mutex
+}
+
+// OpenPKCS11 loads the module, opens a session on the slot and finds the
+// key pair labelled cfg.KeyLabel. RSA keys can sign RS* and PS* tokens and
+// EC keys ES* tokens.
+func OpenPKCS11(cfg PKCS11Config) (Signer, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	ctx := pkcs11.New(cfg.Module)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %s", cfg.Module)
+	}
+	owned := true
+	if err := ctx.Initialize(); errors.Is(err, pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED)) {
+		owned = false
+	} else if err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize PKCS#11 module: %w", err)
+	}
+	s := &pkcs11Signer{ctx: ctx, owned: owned}
+	if err := s.open(cfg); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// open starts the session and loads the key pair
+func (s *pkcs11Signer) open(cfg PKCS11Config) error {
+	session, err := s.ctx.OpenSession(cfg.Slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return fmt.Errorf("failed to open a session on slot %d: %w", cfg.Slot, err)
+	}
+	s.session = session
+	if cfg.PIN != "" {
+		err := s.ctx.Login(session, pkcs11.CKU_USER, cfg.PIN)
+		if err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+			return fmt.Errorf("PKCS#11 login failed: %w", err)
+		}
+	}
+
+	if s.key, err = s.find(pkcs11.CKO_PRIVATE_KEY, cfg.KeyLabel); err != nil {
+		return err
+	}
+	publicKey, err := s.find(pkcs11.CKO_PUBLIC_KEY, cfg.KeyLabel)
+	if err != nil {
+		return err
+	}
+	s.public, err = s.readPublicKey(publicKey)
+	return err
+}
+
+// find returns the single object of the class with the given label
+func (s *pkcs11Signer) find(class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, fmt.Errorf("PKCS#11 object search failed: %w", err)
+	}
+	objects, _, err := s.ctx.FindObjects(s.session, 2)
+	if finalErr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("PKCS#11 object search failed: %w", err)
+	}
+
+	kind := "private"
+	if class == pkcs11.CKO_PUBLIC_KEY {
+		kind = "public"
+	}
+	switch len(objects) {
+	case 0:
+		return 0, fmt.Errorf("%w: no %s key labelled %q", ErrKeyNotFound, kind, label)
+	case 1:
+		return objects[0], nil
+	default:
+		return 0, fmt.Errorf("more than one %s key is labelled %q", kind, label)
+	}
+}
+
+// readPublicKey reads an RSA or EC public key object
+func (s *pkcs11Signer) readPublicKey(object pkcs11.ObjectHandle) (crypto.PublicKey, error) {
+	attrs, err := s.ctx.GetAttributeValue(s.session, object, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the public key type: %w", err)
+	}
+	switch keyType := bytesToUint(attrs[0].Value); keyType {
+	case pkcs11.CKK_RSA:
+		attrs, err := s.ctx.GetAttributeValue(s.session, object, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the RSA public key: %w", err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(attrs[0].Value),
+			E: int(new(big.Int).SetBytes(attrs[1].Value).Int64()),
+		}, nil
+	case pkcs11.CKK_EC:
+		attrs, err := s.ctx.GetAttributeValue(s.session, object, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the EC public key: %w", err)
+		}
+		return parseECPoint(attrs[0].Value, attrs[1].Value)
+	default:
+		return nil, fmt.Errorf("unsupported PKCS#11 key type %d", keyType)
+	}
+}
+
+// Public returns the public half of the key
+func (s *pkcs11Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign signs digest on the token. RSA keys use PKCS #1 v1.5 unless opts is
+// *rsa.PSSOptions; ECDSA signatures are returned ASN.1 encoded like those of
+// crypto/ecdsa.
+func (s *pkcs11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	mechanism, input, err := s.mechanism(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{mechanism}, s.key); err != nil {
+		return nil, fmt.Errorf("PKCS#11 sign failed: %w", err)
+	}
+	signature, err := s.ctx.Sign(s.session, input)
+	if err != nil {
+		return nil, fmt.Errorf("PKCS#11 sign failed: %w", err)
+	}
+
+	if _, ok := s.public.(*ecdsa.PublicKey); ok {
+		half := len(signature) / 2
+		return asn1.Marshal(struct{ R, S *big.Int }{
+			new(big.Int).SetBytes(signature[:half]),
+			new(big.Int).SetBytes(signature[half:]),
+		})
+	}
+	return signature, nil
+}
+
+// mechanism picks the PKCS#11 mechanism and its input for a digest
+func (s *pkcs11Signer) mechanism(digest []byte, opts crypto.SignerOpts) (*pkcs11.Mechanism, []byte, error) {
+	hash := opts.HashFunc()
+	if len(digest) != hash.Size() {
+		return nil, nil, fmt.Errorf("digest length %d does not match %v", len(digest), hash)
+	}
+
+	switch s.public.(type) {
+	case *ecdsa.PublicKey:
+		return pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil), digest, nil
+	case *rsa.PublicKey:
+		if pss, ok := opts.(*rsa.PSSOptions); ok {
+			hashMech, mgf, err := pssParameters(hash)
+			if err != nil {
+				return nil, nil, err
+			}
+			saltLength := pss.SaltLength
+			if saltLength == rsa.PSSSaltLengthEqualsHash || saltLength == rsa.PSSSaltLengthAuto {
+				saltLength = hash.Size()
+			}
+			params := pkcs11.NewPSSParams(hashMech, mgf, uint(saltLength))
+			return pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_PSS, params), digest, nil
+		}
+		prefix, ok := digestInfoPrefixes[hash]
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported hash %v for RSA signing", hash)
+		}
+		return pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil), append(append([]byte{}, prefix...), digest...), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %T", s.public)
+	}
+}
+
+// Close closes the session and releases the module, finalizing it only if
+// this signer initialized it
+func (s *pkcs11Signer) Close() error {
+	var err error
+	if s.session != 0 {
+		err = s.ctx.CloseSession(s.session)
+		s.session = 0
+	}
+	if s.owned {
+		if finalizeErr := s.ctx.Finalize(); err == nil {
+			err = finalizeErr
+		}
+	}
+	s.ctx.Destroy()
+	return err
+}
+
+// digestInfoPrefixes are the DER DigestInfo headers CKM_RSA_PKCS expects in
+// front of the digest (RFC 8017 section 9.2)
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// pssParameters maps a hash to its PKCS#11 hash and MGF1 mechanisms
+func pssParameters(hash crypto.Hash) (uint, uint, error) {
+	switch hash {
+	case crypto.SHA256:
+		return pkcs11.CKM_SHA256, pkcs11.CKG_MGF1_SHA256, nil
+	case crypto.SHA384:
+		return pkcs11.CKM_SHA384, pkcs11.CKG_MGF1_SHA384, nil
+	case crypto.SHA512:
+		return pkcs11.CKM_SHA512, pkcs11.CKG_MGF1_SHA512, nil
+	default:
+		return 0, 0, fmt.Errorf("unsupported hash %v for RSA-PSS signing", hash)
+	}
+}
+
+// namedCurves maps EC parameter OIDs to curves
+var namedCurves = []struct {
+	oid   asn1.ObjectIdentifier
+	curve elliptic.Curve
+}{
+	{asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}, elliptic.P256()},
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 34}, elliptic.P384()},
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 35}, elliptic.P521()},
+}
+
+// parseECPoint decodes CKA_EC_PARAMS and the DER-wrapped CKA_EC_POINT
+func parseECPoint(params, point []byte) (*ecdsa.PublicKey, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return nil, fmt.Errorf("unsupported EC parameters: %w", err)
+	}
+	var curve elliptic.Curve
+	for _, named := range namedCurves {
+		if named.oid.Equal(oid) {
+			curve = named.curve
+		}
+	}
+	if curve == nil {
+		return nil, fmt.Errorf("unsupported EC curve %v", oid)
+	}
+
+	var raw []byte
+	if _, err := asn1.Unmarshal(point, &raw); err != nil {
+		// Some modules return the point without the OCTET STRING wrapper
+		raw = point
+	}
+	x, y := elliptic.Unmarshal(curve, raw)
+	if x == nil {
+		return nil, fmt.Errorf("invalid EC point")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// bytesToUint decodes a native-endian CK_ULONG attribute value
+func bytesToUint(b []byte) uint {
+	var n uint
+	for i := len(b) - 1; i >= 0; i-- {
+		n = n<<8 | uint(b[i])
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+//go:build pkcs11 && cgo
+
+package signer_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/interface/signer"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+// The test runs against an initialized token, e.g. with SoftHSM:
+//
+//	softhsm2-util --init-token --free --label jwt-test --so-pin 1234 --pin 1234
+//	JWT_TEST_PKCS11_MODULE=/usr/lib/softhsm/libsofthsm2.so \
+//	JWT_TEST_PKCS11_SLOT=<slot from the output> JWT_TEST_PKCS11_PIN=1234 \
+//	go test -tags pkcs11 ./internal/interface/signer
+func pkcs11Config(t *testing.T) signer.PKCS11Config {
+	t.Helper()
+	module := os.Getenv("JWT_TEST_PKCS11_MODULE")
+	if module == "" {
+		t.Skip("JWT_TEST_PKCS11_MODULE is not set")
+	}
+	slot, err := strconv.ParseUint(os.Getenv("JWT_TEST_PKCS11_SLOT"), 10, 0)
+	if err != nil {
+		t.Fatalf("invalid JWT_TEST_PKCS11_SLOT: %v", err)
+	}
+	return signer.PKCS11Config{Module: module, Slot: uint(slot), PIN: os.Getenv("JWT_TEST_PKCS11_PIN")}
+}
+
+// generateKeyPair creates a session key pair with a random label on the
+// token; session objects disappear when the session closes
+func generateKeyPair(t *testing.T, cfg signer.PKCS11Config, ec bool) string {
+	t.Helper()
+	ctx := pkcs11.New(cfg.Module)
+	if err := ctx.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	session, err := ctx.OpenSession(cfg.Slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.Login(session, pkcs11.CKU_USER, cfg.PIN); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ctx.Logout(session)
+		ctx.CloseSession(session)
+		ctx.Finalize()
+		ctx.Destroy()
+	})
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	label := "jwt-test-" + hex.EncodeToString(suffix)
+
+	mechanism := pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)
+	public := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_MODULUS_BITS, 2048),
+		pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, []byte{1, 0, 1}),
+	}
+	if ec {
+		mechanism = pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)
+		public = []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+			// DER encoded OID of P-256
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}),
+		}
+	}
+	private := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+	}
+	if _, _, err := ctx.GenerateKeyPair(session, []*pkcs11.Mechanism{mechanism}, public, private); err != nil {
+		t.Fatal(err)
+	}
+	return label
+}
+
+func TestPKCS11Signer(t *testing.T) {
+	tests := []struct {
+		algorithm hash.Algorithm
+		ec        bool
+	}{
+		{hash.RS256, false},
+		{hash.PS256, false},
+		{hash.ES256, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.algorithm), func(t *testing.T) {
+			cfg := pkcs11Config(t)
+			cfg.KeyLabel = generateKeyPair(t, cfg, tt.ec)
+
+			hsm, err := signer.OpenPKCS11(cfg)
+			if err != nil {
+				t.Fatalf("OpenPKCS11 failed: %v", err)
+			}
+			defer hsm.Close()
+
+			hasher, err := hash.NewHasher(tt.algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := jwtusecase.NewSignerEncoder(hasher.(hash.KeyHasher), hsm).Encode(map[string]any{"sub": "alice"})
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			if _, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithVerificationKey(hsm.Public())).Verify(token); err != nil {
+				t.Errorf("Verify failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestPKCS11Signer_UnknownLabel(t *testing.T) {
+	cfg := pkcs11Config(t)
+	cfg.KeyLabel = "no-such-key"
+	if _, err := signer.OpenPKCS11(cfg); err == nil {
+		t.Error("Expected error for an unknown key label")
+	}
+}
@@ -0,0 +1,125 @@
+// Package crack audits HMAC-signed tokens for guessable secrets by trying
+// candidate secrets from a wordlist. Use it only on tokens you are
+// authorized to test.
+package crack
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+)
+
+// Result is the outcome of a wordlist run
+type Result struct {
+	// Found reports whether a candidate verified the signature
+	Found bool
+	// Secret is the matching candidate
+	Secret string
+	// Tried counts the candidates checked
+	Tried int64
+	// Algorithm is the token's HMAC algorithm
+	Algorithm hash.Algorithm
+}
+
+// Run tries each line of the wordlist as the token's HMAC secret using the
+// given number of workers (runtime.NumCPU() when workers < 1). It stops at
+// the first match or when ctx is cancelled.
+func Run(ctx context.Context, token string, wordlist io.Reader, workers int) (*Result, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT format: expected 3 parts, got %d", len(parts))
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT format: header is not valid base64")
+	}
+	header, err := jwt.ParseObject(headerJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT format: header is not valid JSON")
+	}
+	alg, _ := header["alg"].(string)
+	algorithm := hash.Algorithm(alg)
+	if algorithm != hash.HS256 && algorithm != hash.HS384 && algorithm != hash.HS512 {
+		return nil, fmt.Errorf("%w: %v: only HMAC-signed tokens can be audited", hash.ErrUnsupportedAlgorithm, header["alg"])
+	}
+	hasher, err := hash.NewHasher(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT format: signature is not valid base64")
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	signatureInput := []byte(parts[0] + "." + parts[1])
+	candidates := make(chan string, workers*4)
+	result := &Result{Algorithm: algorithm}
+	var tried atomic.Int64
+	var once sync.Once
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for candidate := range candidates {
+				tried.Add(1)
+				if hasher.Verify(signatureInput, signature, []byte(candidate)) == nil {
+					once.Do(func() {
+						result.Found = true
+						result.Secret = candidate
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	scanErr := feed(ctx, wordlist, candidates)
+	close(candidates)
+	wg.Wait()
+
+	result.Tried = tried.Load()
+	if scanErr != nil {
+		return result, fmt.Errorf("failed to read wordlist: %w", scanErr)
+	}
+	if !result.Found && parent.Err() != nil {
+		return result, parent.Err()
+	}
+	return result, nil
+}
+
+// feed sends the wordlist's lines to candidates until the list ends or ctx
+// is done
+func feed(ctx context.Context, wordlist io.Reader, candidates chan<- string) error {
+	scanner := bufio.NewScanner(wordlist)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		candidate := strings.TrimRight(scanner.Text(), "\r")
+		if candidate == "" {
+			continue
+		}
+		select {
+		case candidates <- candidate:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return scanner.Err()
+}
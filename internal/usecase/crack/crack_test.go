@@ -0,0 +1,70 @@
+package crack_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"jwt/internal/usecase/crack"
+)
+
+// weakToken signs a token with a short secret, which the HMAC hashers refuse
+// to do
+func weakToken(secret string) string {
+	input := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"audit"}`))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func wordlist(n int, extra ...string) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "candidate-%d\n", i)
+	}
+	for _, w := range extra {
+		b.WriteString(w + "\r\n")
+	}
+	return b.String()
+}
+
+func TestRun(t *testing.T) {
+	token := weakToken("hunter2")
+
+	result, err := crack.Run(context.Background(), token, strings.NewReader(wordlist(5000, "hunter2")), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Found || result.Secret != "hunter2" || result.Algorithm != "HS256" {
+		t.Errorf("Expected secret to be found, got %+v", result)
+	}
+
+	result, err = crack.Run(context.Background(), token, strings.NewReader(wordlist(1000)), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Found || result.Tried != 1000 {
+		t.Errorf("Expected all 1000 candidates to be tried without a match, got %+v", result)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	rs256 := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256"}`)) + ".e30.sig"
+	if _, err := crack.Run(context.Background(), rs256, strings.NewReader("a\n"), 1); err == nil {
+		t.Error("Expected error for a non-HMAC token")
+	}
+	if _, err := crack.Run(context.Background(), "not-a-token", strings.NewReader("a\n"), 1); err == nil {
+		t.Error("Expected error for a malformed token")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := crack.Run(ctx, weakToken("x"), strings.NewReader(wordlist(100)), 2); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
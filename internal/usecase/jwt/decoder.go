@@ -1,8 +1,9 @@
 package jwt
 
 import (
+	"crypto"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -15,13 +16,125 @@ import (
 // Decoder implements the JWT decoder use case
 type Decoder struct {
 	hasher hash.Hasher
+	keys   jwt.KeySource
+	parsed crypto.PublicKey
+	policy jwt.Policy
+	replay jwt.ReplayStore
+	revoke jwt.RevocationChecker
+	crit   *jwt.Extensions
+	// maxSize enables strict parsing when positive
+	maxSize int
+}
+
+// Option configures optional Decoder behaviour
+type Option func(*Decoder)
+
+// WithKey sets the key used for signature validation. Without it the key is
+// read from JWT_PUBLIC_KEY for RSA algorithms and JWT_SECRET_KEY otherwise.
+func WithKey(key []byte) Option {
+	return WithKeySource(jwt.StaticKey(key))
+}
+
+// WithKeySource sets where signature validation keys are looked up
+func WithKeySource(keys jwt.KeySource) Option {
+	return func(d *Decoder) {
+		d.keys = keys
+		d.parsed = nil
+	}
+}
+
+// WithVerificationKey sets a key parsed once by the hasher's
+// ParseVerificationKey, avoiding a PEM or JWK decode per token. The hasher
+// must implement hash.KeyHasher.
+func WithVerificationKey(key crypto.PublicKey) Option {
+	return func(d *Decoder) {
+		d.keys = nil
+		d.parsed = key
+	}
+}
+
+// WithPolicy sets the claim checks applied during validation
+func WithPolicy(policy jwt.Policy) Option {
+	return func(d *Decoder) {
+		d.policy = policy
+	}
+}
+
+// WithReplayStore rejects tokens whose jti was already accepted. Tokens
+// without jti or exp claims are rejected while a store is configured.
+func WithReplayStore(store jwt.ReplayStore) Option {
+	return func(d *Decoder) {
+		d.replay = store
+	}
+}
+
+// WithRevocationChecker rejects tokens the checker reports as revoked
+func WithRevocationChecker(checker jwt.RevocationChecker) Option {
+	return func(d *Decoder) {
+		d.revoke = checker
+	}
+}
+
+// WithExtensions sets the registry of header extensions a crit header may
+// list; jwt.DefaultExtensions is used otherwise
+func WithExtensions(extensions *jwt.Extensions) Option {
+	return func(d *Decoder) {
+		d.crit = extensions
+	}
+}
+
+// WithStrictParsing rejects tokens larger than maxTokenSize bytes (or
+// jwt.DefaultMaxTokenSize when not positive), segments that are not
+// canonical base64url and JSON objects with duplicate member names
+func WithStrictParsing(maxTokenSize int) Option {
+	return func(d *Decoder) {
+		if maxTokenSize <= 0 {
+			maxTokenSize = jwt.DefaultMaxTokenSize
+		}
+		d.maxSize = maxTokenSize
+	}
 }
 
 // NewDecoder creates a new JWT decoder instance
-func NewDecoder(hasher hash.Hasher) jwt.Decoder {
-	return &Decoder{
+func NewDecoder(hasher hash.Hasher, opts ...Option) jwt.Decoder {
+	return newDecoder(hasher, opts...)
+}
+
+// NewVerifier creates a validator sharing the decoder's validation pipeline
+func NewVerifier(hasher hash.Hasher, opts ...Option) jwt.Verifier {
+	return newDecoder(hasher, opts...)
+}
+
+// newDecoder applies the options to a new Decoder
+func newDecoder(hasher hash.Hasher, opts ...Option) *Decoder {
+	d := &Decoder{
 		hasher: hasher,
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// verificationKeys returns the candidate keys for a token header, falling
+// back to the environment when no key source is configured
+func (d *Decoder) verificationKeys(header map[string]any) ([][]byte, error) {
+	if d.keys != nil {
+		keys, err := d.keys.VerificationKeys(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve verification key: %w", err)
+		}
+		return keys, nil
+	}
+	env := "JWT_SECRET_KEY"
+	if !strings.HasPrefix(d.hasher.Name(), "HS") {
+		env = "JWT_PUBLIC_KEY"
+	}
+	key := []byte(os.Getenv(env))
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%s environment variable is required for validation", env)
+	}
+	return [][]byte{key}, nil
 }
 
 // isPowerShell checks if we're running in PowerShell
@@ -34,14 +147,6 @@ func (d *Decoder) GenerateTestToken(algorithm hash.Algorithm) (string, error) {
 		return "", fmt.Errorf("failed to create hasher: %w", err)
 	}
 
-	// Header
-	header := map[string]string{
-		"alg": string(algorithm),
-		"typ": "JWT",
-	}
-	headerJSON, _ := json.Marshal(header)
-	headerBase64 := base64.RawURLEncoding.EncodeToString(headerJSON)
-
 	// Payload with realistic claims
 	payload := map[string]interface{}{
 		"iss":   "test-issuer",
@@ -63,87 +168,225 @@ func (d *Decoder) GenerateTestToken(algorithm hash.Algorithm) (string, error) {
 			"employee_id": "EMP123",
 		},
 	}
-	payloadJSON, _ := json.Marshal(payload)
-	payloadBase64 := base64.RawURLEncoding.EncodeToString(payloadJSON)
 
-	// Create signature
-	secretKey := "your-super-secret-key-123!@#$%^&*()"
-	signatureInput := headerBase64 + "." + payloadBase64
-	signature := hasher.Sign([]byte(signatureInput), []byte(secretKey))
+	// Sign with the well-known test secret
+	return NewEncoder(hasher, []byte(jwt.TestSecretKey)).Encode(payload)
+}
 
-	// Combine all parts
-	token := fmt.Sprintf("%s.%s.%s", headerBase64, payloadBase64, signature)
-	return token, nil
+// segments holds the decoded parts of a compact token
+type segments struct {
+	parts     []string
+	header    []byte
+	payload   []byte
+	signature []byte
+	headerMap map[string]any
 }
 
-// Decode decodes a JWT token and returns the decoded parts and any error
-func (d *Decoder) Decode(token string, validate bool) (string, error) {
+// split checks the token's structure and decodes the header
+func (d *Decoder) split(token string) (*segments, error) {
 	if token == "" {
-		return "", fmt.Errorf("empty token provided")
+		return nil, fmt.Errorf("empty token provided")
+	}
+	strict := d.maxSize > 0
+	if strict && len(token) > d.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, the limit is %d", jwt.ErrTokenTooLarge, len(token), d.maxSize)
 	}
 
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
-		return "", fmt.Errorf("invalid JWT format: expected 3 parts, got %d", len(parts))
+		return nil, fmt.Errorf("%w: expected 3 parts, got %d", jwt.ErrMalformedToken, len(parts))
 	}
 
-	// Validate each part is valid base64
+	// Decode each part once, checking it is valid base64
+	var decoded [3][]byte
 	for i, part := range parts {
-		if _, err := base64.RawURLEncoding.DecodeString(part); err != nil {
-			return "", fmt.Errorf("invalid JWT format: part %d is not valid base64", i+1)
+		var b []byte
+		var err error
+		if strict {
+			b, err = jwt.DecodeSegmentStrict(part)
+		} else {
+			b, err = base64.RawURLEncoding.DecodeString(part)
+		}
+		if errors.Is(err, jwt.ErrNonCanonicalBase64) {
+			return nil, fmt.Errorf("%w: part %d: %w", jwt.ErrMalformedToken, i+1, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: part %d is not valid base64", jwt.ErrMalformedToken, i+1)
+		}
+		decoded[i] = b
+	}
+	if strict {
+		if err := jwt.CheckDuplicateMembers(decoded[0]); err != nil {
+			return nil, fmt.Errorf("%w: header has a %w", jwt.ErrMalformedToken, err)
+		}
+		if err := jwt.CheckDuplicateMembers(decoded[1]); err != nil {
+			return nil, fmt.Errorf("%w: payload has a %w", jwt.ErrMalformedToken, err)
 		}
 	}
 
 	// Parse header to get algorithm
-	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	headerMap, err := jwt.ParseObject(decoded[0])
 	if err != nil {
-		return "", fmt.Errorf("invalid JWT format: header is not valid base64")
+		return nil, fmt.Errorf("%w: header is not valid JSON", jwt.ErrMalformedToken)
 	}
 
-	var headerMap map[string]any
-	if err := json.Unmarshal(header, &headerMap); err != nil {
-		return "", fmt.Errorf("invalid JWT format: header is not valid JSON")
+	return &segments{parts: parts, header: decoded[0], payload: decoded[1], signature: decoded[2], headerMap: headerMap}, nil
+}
+
+// splitChecked splits the token and checks that its algorithm matches the
+// decoder's hasher
+func (d *Decoder) splitChecked(token string) (*segments, error) {
+	s, err := d.split(token)
+	if err != nil {
+		return nil, err
+	}
+	if alg, ok := s.headerMap["alg"].(string); !ok || alg != d.hasher.Name() {
+		return nil, fmt.Errorf("%w: %v", hash.ErrUnsupportedAlgorithm, s.headerMap["alg"])
 	}
+	return s, nil
+}
 
-	// Verify algorithm matches
-	if alg, ok := headerMap["alg"].(string); !ok || alg != d.hasher.Name() {
-		return "", fmt.Errorf("unsupported algorithm: %v", headerMap["alg"])
+// checkCritical rejects tokens whose crit header is malformed or lists an
+// extension the decoder's registry does not understand
+func (d *Decoder) checkCritical(s *segments) error {
+	extensions := d.crit
+	if extensions == nil {
+		extensions = jwt.DefaultExtensions
 	}
+	return extensions.Check(s.headerMap)
+}
 
-	// Validate signature if requested
-	if validate {
-		signatureInput := parts[0] + "." + parts[1]
-		key := []byte(os.Getenv("JWT_SECRET_KEY"))
-		if len(key) == 0 {
-			return "", fmt.Errorf("JWT_SECRET_KEY environment variable is required for validation")
+// verifySignature checks the signature against each candidate key in turn.
+// The result is ErrSignatureInvalid if any key could be used, otherwise the
+// first key's error.
+func (d *Decoder) verifySignature(s *segments) error {
+	signature := s.signature
+	signatureInput := []byte(s.parts[0] + "." + s.parts[1])
+
+	if d.parsed != nil {
+		keyed, ok := d.hasher.(hash.KeyHasher)
+		if !ok {
+			return fmt.Errorf("%w: the %s hasher does not accept parsed keys", hash.ErrInvalidKey, d.hasher.Name())
 		}
-		if !d.hasher.Verify([]byte(signatureInput), parts[2], key) {
-			return "", fmt.Errorf("invalid signature")
+		return keyed.VerifyWithKey(signatureInput, signature, d.parsed)
+	}
+
+	keys, err := d.verificationKeys(s.headerMap)
+	if err != nil {
+		return err
+	}
+
+	var keyErr error
+	for _, key := range keys {
+		err := d.hasher.Verify(signatureInput, signature, key)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, hash.ErrSignatureInvalid):
+			keyErr = hash.ErrSignatureInvalid
+		case keyErr == nil:
+			keyErr = err
 		}
 	}
+	if keyErr == nil {
+		return hash.ErrSignatureInvalid
+	}
+	return keyErr
+}
 
-	// Decode payload
-	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+// validateClaims applies the policy and, when configured, the revocation and
+// replay checks. Replay is checked last so rejected tokens are not recorded.
+func (d *Decoder) validateClaims(token *jwt.Token) error {
+	claims := token.Claims
+	if err := d.policy.Validate(claims); err != nil {
+		return err
+	}
+	if d.revoke != nil {
+		if err := d.revoke.CheckRevoked(token); err != nil {
+			return err
+		}
+	}
+	if d.replay != nil {
+		return jwt.CheckReplay(d.replay, claims, d.policy.Leeway)
+	}
+	return nil
+}
+
+// Verify checks the token's signature and claims and returns its contents
+func (d *Decoder) Verify(token string) (*jwt.Token, error) {
+	s, err := d.splitChecked(token)
 	if err != nil {
-		return "", fmt.Errorf("invalid JWT format: payload is not valid base64")
+		return nil, err
+	}
+	if err := d.checkCritical(s); err != nil {
+		return nil, err
+	}
+	if err := d.verifySignature(s); err != nil {
+		return nil, err
 	}
 
-	// Parse header and payload as JSON and pretty print
-	var headerObj, payloadObj map[string]interface{}
-	if err := json.Unmarshal(header, &headerObj); err != nil {
-		// If can't parse as JSON, use raw string
-	} else {
-		headerBytes, err := json.MarshalIndent(headerObj, "", "  ")
-		if err == nil {
-			header = headerBytes
+	claims, err := jwt.ParseObject(s.payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload is not valid JSON", jwt.ErrMalformedToken)
+	}
+	verified := &jwt.Token{Raw: token, Header: s.headerMap, Claims: claims}
+	if err := d.validateClaims(verified); err != nil {
+		return nil, err
+	}
+	return verified, nil
+}
+
+// Parse decodes the token's header and payload without verifying it. Unlike
+// Decode it accepts any algorithm, so tokens of different kinds can be
+// inspected side by side.
+func (d *Decoder) Parse(token string) (*jwt.Token, error) {
+	s, err := d.split(token)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := jwt.ParseObject(s.payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload is not valid JSON", jwt.ErrMalformedToken)
+	}
+	return &jwt.Token{Raw: token, Header: s.headerMap, Claims: claims}, nil
+}
+
+// Decode decodes a JWT token and returns the decoded parts and any error
+func (d *Decoder) Decode(token string, validate bool) (string, error) {
+	s, err := d.splitChecked(token)
+	if err != nil {
+		return "", err
+	}
+	header, payload := s.header, s.payload
+
+	// Validate signature if requested
+	if validate {
+		if err := d.checkCritical(s); err != nil {
+			return "", err
+		}
+		if err := d.verifySignature(s); err != nil {
+			return "", err
 		}
 	}
 
-	if err := json.Unmarshal(payload, &payloadObj); err != nil {
+	// Pretty print header and payload, keeping key order and number formatting
+	if headerBytes, err := jwt.Indent(header); err == nil {
+		header = headerBytes
+	}
+
+	payloadObj, err := jwt.ParseObject(payload)
+	if err != nil {
 		// If can't parse as JSON, use raw string
+		if validate {
+			return "", fmt.Errorf("%w: payload is not valid JSON", jwt.ErrMalformedToken)
+		}
 	} else {
-		payloadBytes, err := json.MarshalIndent(payloadObj, "", "  ")
-		if err == nil {
+		if validate {
+			if err := d.validateClaims(&jwt.Token{Raw: token, Header: s.headerMap, Claims: payloadObj}); err != nil {
+				return "", err
+			}
+		}
+		if payloadBytes, err := jwt.Indent(payload); err == nil {
 			payload = payloadBytes
 		}
 	}
@@ -155,3 +398,13 @@ func (d *Decoder) Decode(token string, validate bool) (string, error) {
 	}
 	return output, nil
 }
+
+// VerifyClaims verifies the token and decodes its payload into a new T,
+// typically a struct embedding jwt.RegisteredClaims
+func VerifyClaims[T any](verifier jwt.Verifier, token string) (*T, error) {
+	verified, err := verifier.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.UnmarshalClaims[T](verified)
+}
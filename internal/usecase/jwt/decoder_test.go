@@ -0,0 +1,425 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+// keyList is a KeySource returning fixed candidates
+type keyList [][]byte
+
+func (k keyList) VerificationKeys(map[string]any) ([][]byte, error) {
+	return k, nil
+}
+
+func TestDecoder_Verify(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("verify-test-secret-with-32-bytes!!")
+	now := time.Now()
+	sign := func(claims map[string]any) string {
+		token, err := jwtusecase.NewEncoder(hasher, secret).Encode(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	policy := jwt.Policy{Issuer: "issuer", Audience: "api"}
+
+	tests := []struct {
+		name        string
+		token       string
+		opts        []jwtusecase.Option
+		errContains string
+	}{
+		{
+			name:  "Valid token",
+			token: sign(map[string]any{"iss": "issuer", "aud": "api", "exp": now.Add(time.Minute).Unix()}),
+			opts:  []jwtusecase.Option{jwtusecase.WithKey(secret), jwtusecase.WithPolicy(policy)},
+		},
+		{
+			name:  "Second candidate key matches",
+			token: sign(map[string]any{"iss": "issuer", "aud": "api"}),
+			opts:  []jwtusecase.Option{jwtusecase.WithKeySource(keyList{[]byte("old-secret"), secret}), jwtusecase.WithPolicy(policy)},
+		},
+		{
+			name:        "Wrong key",
+			token:       sign(map[string]any{"iss": "issuer", "aud": "api"}),
+			opts:        []jwtusecase.Option{jwtusecase.WithKey([]byte("wrong")), jwtusecase.WithPolicy(policy)},
+			errContains: "invalid signature",
+		},
+		{
+			name:        "Expired",
+			token:       sign(map[string]any{"iss": "issuer", "aud": "api", "exp": now.Add(-time.Minute).Unix()}),
+			opts:        []jwtusecase.Option{jwtusecase.WithKey(secret), jwtusecase.WithPolicy(policy)},
+			errContains: "token is expired",
+		},
+		{
+			name:        "Wrong audience",
+			token:       sign(map[string]any{"iss": "issuer", "aud": "web"}),
+			opts:        []jwtusecase.Option{jwtusecase.WithKey(secret), jwtusecase.WithPolicy(policy)},
+			errContains: "invalid audience",
+		},
+		{
+			name:        "Payload is not an object",
+			token:       sign(nil),
+			opts:        []jwtusecase.Option{jwtusecase.WithKey(secret)},
+			errContains: "payload is not valid JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwtusecase.NewVerifier(hasher, tt.opts...).Verify(tt.token)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error to contain %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if token.Claims["iss"] != "issuer" || token.Header["alg"] != "HS256" || token.Raw != tt.token {
+				t.Errorf("Unexpected token contents: %+v", token)
+			}
+		})
+	}
+}
+
+func TestDecoder_VerifyErrors(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwtusecase.NewEncoder(hasher, []byte("verify-test-secret-with-32-bytes!!")).Encode(map[string]any{"sub": "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs384, err := hash.NewHasher(hash.HS384)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		hasher  hash.Hasher
+		keys    keyList
+		wantErr error
+	}{
+		{"Only unusable keys", hasher, keyList{{}}, hash.ErrInvalidKey},
+		{"Usable key does not match", hasher, keyList{{}, []byte("wrong")}, hash.ErrSignatureInvalid},
+		{"Algorithm mismatch", hs384, keyList{[]byte("wrong")}, hash.ErrUnsupportedAlgorithm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwtusecase.NewVerifier(tt.hasher, jwtusecase.WithKeySource(tt.keys)).Verify(token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("verify-test-secret-with-32-bytes!!")
+	token, err := jwtusecase.NewEncoder(hasher, secret).Encode(map[string]any{
+		"sub":   "user-1",
+		"aud":   []string{"api", "web"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "read",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type claims struct {
+		jwt.RegisteredClaims
+		Scope string `json:"scope"`
+	}
+	verifier := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(secret), jwtusecase.WithPolicy(jwt.Policy{Audience: "web"}))
+
+	got, err := jwtusecase.VerifyClaims[claims](verifier, token)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Subject != "user-1" || got.Scope != "read" || !got.Audience.Contains("api") || got.ExpiresAt == nil {
+		t.Errorf("Unexpected claims: %+v", got)
+	}
+
+	if _, err := jwtusecase.VerifyClaims[claims](verifier, token+"x"); err == nil {
+		t.Error("Expected error for tampered token")
+	}
+}
+
+func TestDecoder_DecodePreservesNumbers(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("verify-test-secret-with-32-bytes!!")
+	exp := time.Now().Add(time.Hour).Unix()
+	payload := fmt.Sprintf(`{"sub":"user","id":1234567890123456789,"exp":%d.0,"ratio":1e-7}`, exp)
+	input := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"HS256"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(payload))
+	signature, err := hasher.Sign([]byte(input), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := input + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	output, err := jwtusecase.NewDecoder(hasher, jwtusecase.WithKey(secret)).Decode(token, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := fmt.Sprintf("Header: {\n  \"typ\": \"JWT\",\n  \"alg\": \"HS256\"\n}\n"+
+		"Payload: {\n  \"sub\": \"user\",\n  \"id\": 1234567890123456789,\n  \"exp\": %d.0,\n  \"ratio\": 1e-7\n}\n"+
+		"Signature: Valid", exp)
+	if output != want {
+		t.Errorf("Expected output %q, got %q", want, output)
+	}
+
+	verified, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(secret)).Verify(token)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if verified.Claims["id"] != json.Number("1234567890123456789") {
+		t.Errorf("Expected id to be kept as json.Number, got %#v", verified.Claims["id"])
+	}
+}
+
+// usedIDs is a minimal ReplayStore for decoder tests
+type usedIDs map[string]bool
+
+func (u usedIDs) Use(jti string, expiresAt time.Time) error {
+	if u[jti] {
+		return jwt.ErrReplayed
+	}
+	u[jti] = true
+	return nil
+}
+
+func TestDecoder_ReplayStore(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("verify-test-secret-with-32-bytes!!")
+	encoder := jwtusecase.NewEncoder(hasher, secret)
+	exp := time.Now().Add(time.Hour).Unix()
+	oneTime, _ := encoder.Encode(map[string]any{"jti": "reset-1", "exp": exp})
+	noID, _ := encoder.Encode(map[string]any{"exp": exp})
+	noExp, _ := encoder.Encode(map[string]any{"jti": "reset-2"})
+
+	verifier := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(secret), jwtusecase.WithReplayStore(usedIDs{}))
+
+	if _, err := verifier.Verify(oneTime); err != nil {
+		t.Fatalf("First use failed: %v", err)
+	}
+	if _, err := verifier.Verify(oneTime); !errors.Is(err, jwt.ErrReplayed) {
+		t.Errorf("Expected ErrReplayed on reuse, got %v", err)
+	}
+	if _, err := verifier.Verify(noID); err == nil || !strings.Contains(err.Error(), "invalid jti claim") {
+		t.Errorf("Expected missing jti to be rejected, got %v", err)
+	}
+	if _, err := verifier.Verify(noExp); err == nil || !strings.Contains(err.Error(), "invalid exp claim") {
+		t.Errorf("Expected missing exp to be rejected, got %v", err)
+	}
+}
+
+func TestDecoder_RevocationChecker(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("verify-test-secret-with-32-bytes!!")
+	encoder := jwtusecase.NewEncoder(hasher, secret)
+	revoked, _ := encoder.Encode(map[string]any{"jti": "stolen"})
+	valid, _ := encoder.Encode(map[string]any{"jti": "fine"})
+
+	denylist := &jwt.Denylist{JTIs: []string{"stolen"}}
+	decoder := jwtusecase.NewDecoder(hasher, jwtusecase.WithKey(secret), jwtusecase.WithRevocationChecker(denylist))
+
+	if _, err := decoder.Decode(revoked, true); !errors.Is(err, jwt.ErrRevoked) {
+		t.Errorf("Expected ErrRevoked, got %v", err)
+	}
+	if _, err := decoder.Decode(revoked, false); err != nil {
+		t.Errorf("Expected decoding without validation to ignore the denylist, got %v", err)
+	}
+	if _, err := decoder.Decode(valid, true); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestDecoder_CertificateChain(t *testing.T) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Partner Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ = x509.ParseCertificate(caDER)
+	leafDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "partner.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	hasher, err := hash.NewHasher(hash.ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x5c := jwtusecase.WithHeader("x5c", []string{base64.StdEncoding.EncodeToString(leafDER)})
+	signed, err := jwtusecase.NewKeyEncoder(hasher.(hash.KeyHasher), leafKey, x5c).Encode(map[string]any{"sub": "partner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := jwtusecase.NewKeyEncoder(hasher.(hash.KeyHasher), otherKey, x5c).Encode(map[string]any{"sub": "partner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := jwtusecase.NewVerifier(hasher, jwtusecase.WithKeySource(&jwt.CertificateChain{Roots: roots}))
+	if _, err := verifier.Verify(signed); err != nil {
+		t.Errorf("Expected token signed by the leaf key to verify, got %v", err)
+	}
+	if _, err := verifier.Verify(forged); !errors.Is(err, hash.ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for a token not signed by the leaf key, got %v", err)
+	}
+
+	untrusted := jwtusecase.NewVerifier(hasher, jwtusecase.WithKeySource(&jwt.CertificateChain{Roots: x509.NewCertPool()}))
+	if _, err := untrusted.Verify(signed); !errors.Is(err, jwt.ErrCertificateChain) {
+		t.Errorf("Expected ErrCertificateChain for an untrusted chain, got %v", err)
+	}
+}
+
+func TestDecoder_CriticalHeader(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("verify-test-secret-with-32-bytes!!")
+	token, err := jwtusecase.NewEncoder(hasher, secret,
+		jwtusecase.WithHeader("crit", []string{"tenant"}),
+		jwtusecase.WithHeader("tenant", "t1"),
+	).Encode(map[string]any{"sub": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoder := jwtusecase.NewDecoder(hasher, jwtusecase.WithKey(secret))
+	if _, err := decoder.Decode(token, true); !errors.Is(err, jwt.ErrCriticalHeader) {
+		t.Errorf("Expected ErrCriticalHeader for an unknown extension, got %v", err)
+	}
+	if _, err := decoder.Decode(token, false); err != nil {
+		t.Errorf("Expected decoding without validation to ignore crit, got %v", err)
+	}
+	if _, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(secret)).Verify(token); !errors.Is(err, jwt.ErrCriticalHeader) {
+		t.Errorf("Expected ErrCriticalHeader from Verify, got %v", err)
+	}
+
+	verifier := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(secret), jwtusecase.WithExtensions(jwt.NewExtensions("tenant")))
+	if _, err := verifier.Verify(token); err != nil {
+		t.Errorf("Expected a registered extension to be accepted, got %v", err)
+	}
+}
+
+func TestDecoder_StrictParsing(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("verify-test-secret-with-32-bytes!!")
+	sign := func(header, payload string) string {
+		input := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+		signature, err := hasher.Sign([]byte(input), secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+	header := `{"alg":"HS256","typ":"JWT"}`
+	valid := sign(header, `{"sub":"alice"}`)
+	parts := strings.Split(valid, ".")
+
+	// A 32 byte signature leaves two unused bits in its last character
+	last := parts[2][len(parts[2])-1]
+	trailing := parts[0] + "." + parts[1] + "." + parts[2][:len(parts[2])-1] + string(last+1)
+
+	tests := []struct {
+		name        string
+		token       string
+		laxErr      bool
+		errContains string
+	}{
+		{name: "Valid", token: valid},
+		{name: "Oversized", token: sign(header, `{"sub":"`+strings.Repeat("a", 512)+`"}`), errContains: "token exceeds the size limit"},
+		{name: "Duplicate claim", token: sign(header, `{"sub":"alice","sub":"admin"}`), errContains: `payload has a duplicate JSON member "sub"`},
+		{name: "Duplicate header", token: sign(`{"alg":"HS256","alg":"HS256"}`, `{}`), errContains: `header has a duplicate JSON member "alg"`},
+		{name: "Trailing bits", token: trailing, errContains: "part 3: non-canonical base64url: trailing bits are not zero"},
+		{name: "Line break", token: parts[0] + "." + parts[1][:4] + "\n" + parts[1][4:] + "." + parts[2], errContains: "part 2: non-canonical base64url: line breaks are not allowed"},
+		{name: "Padding", token: parts[0] + "=." + parts[1] + "." + parts[2], laxErr: true, errContains: "part 1: non-canonical base64url: padding is not allowed"},
+	}
+
+	lax := jwtusecase.NewDecoder(hasher, jwtusecase.WithKey(secret))
+	strict := jwtusecase.NewDecoder(hasher, jwtusecase.WithKey(secret), jwtusecase.WithStrictParsing(512))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := lax.Decode(tt.token, false); (err != nil) != tt.laxErr {
+				t.Errorf("Lax decoder: unexpected result %v", err)
+			}
+			_, err := strict.Decode(tt.token, true)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+
+	if _, err := jwtusecase.NewDecoder(hasher, jwtusecase.WithStrictParsing(0)).Decode(valid, false); err != nil {
+		t.Errorf("Expected the default limit to accept a small token, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+)
+
+// Encoder implements the JWT encoder use case
+type Encoder struct {
+	hasher hash.Hasher
+	key    []byte
+	keys   jwt.SigningKeySource
+	parsed crypto.PrivateKey
+	header map[string]any
+}
+
+// EncoderOption configures optional Encoder behaviour
+type EncoderOption func(*Encoder)
+
+// WithKeyID sets the kid header of issued tokens
+func WithKeyID(kid string) EncoderOption {
+	return WithHeader("kid", kid)
+}
+
+// WithHeader sets an additional header parameter on issued tokens
+func WithHeader(name string, value any) EncoderOption {
+	return func(e *Encoder) {
+		e.header[name] = value
+	}
+}
+
+// NewEncoder creates a new JWT encoder that signs with the given key
+func NewEncoder(hasher hash.Hasher, key []byte, opts ...EncoderOption) jwt.Encoder {
+	e := &Encoder{
+		hasher: hasher,
+		key:    key,
+		header: map[string]any{"typ": "JWT"},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// NewKeyringEncoder creates an encoder that signs each token with the key
+// source's current key and records its ID in the kid header
+func NewKeyringEncoder(hasher hash.Hasher, keys jwt.SigningKeySource, opts ...EncoderOption) jwt.Encoder {
+	e := NewEncoder(hasher, nil, opts...).(*Encoder)
+	e.keys = keys
+	return e
+}
+
+// NewKeyEncoder creates an encoder that signs with a key parsed once by the
+// hasher's ParseSigningKey, avoiding a PEM or JWK decode per token
+func NewKeyEncoder(hasher hash.KeyHasher, key crypto.PrivateKey, opts ...EncoderOption) jwt.Encoder {
+	e := NewEncoder(hasher, nil, opts...).(*Encoder)
+	e.parsed = key
+	return e
+}
+
+// NewSignerEncoder creates an encoder that signs through a crypto.Signer,
+// such as a KMS, PKCS#11 or ssh-agent client, so the private key never has
+// to be exported
+func NewSignerEncoder(hasher hash.KeyHasher, signer crypto.Signer, opts ...EncoderOption) jwt.Encoder {
+	return NewKeyEncoder(hasher, signer, opts...)
+}
+
+// Encode signs the claims and returns a compact serialized token
+func (e *Encoder) Encode(claims map[string]any) (string, error) {
+	header := make(map[string]any, len(e.header)+1)
+	for name, value := range e.header {
+		header[name] = value
+	}
+	header["alg"] = e.hasher.Name()
+
+	key := e.key
+	if e.keys != nil {
+		kid, current, err := e.keys.SigningKey()
+		if err != nil {
+			return "", fmt.Errorf("failed to select signing key: %w", err)
+		}
+		header["kid"] = kid
+		key = current
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode header: %w", err)
+	}
+	payloadJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	signatureInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(payloadJSON)
+	signature, err := e.sign([]byte(signatureInput), key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token with %s: %w", e.hasher.Name(), err)
+	}
+
+	return signatureInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// sign signs with the parsed key when one was given, otherwise with key
+func (e *Encoder) sign(data, key []byte) ([]byte, error) {
+	if e.parsed != nil {
+		return e.hasher.(hash.KeyHasher).SignWithKey(data, e.parsed)
+	}
+	return e.hasher.Sign(data, key)
+}
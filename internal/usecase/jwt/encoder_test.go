@@ -0,0 +1,130 @@
+package jwt_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("encoder-test-secret-with-32-bytes!")
+
+	encoder := jwtusecase.NewEncoder(hasher, secret, jwtusecase.WithKeyID("key-1"))
+	token, err := encoder.Encode(map[string]any{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	decoder := jwtusecase.NewDecoder(hasher, jwtusecase.WithKey(secret))
+	output, err := decoder.Decode(token, true)
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	for _, want := range []string{`"kid": "key-1"`, `"sub": "alice"`, "Signature: Valid"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+}
+
+func TestEncoder_InvalidKey(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.RS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = jwtusecase.NewEncoder(hasher, []byte("not a pem key")).Encode(map[string]any{"sub": "alice"})
+	if !errors.Is(err, hash.ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestKeyringEncoder_Rotation(t *testing.T) {
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := &jwt.Keyring{
+		Current: "2024-01",
+		Keys:    []jwt.RingKey{{ID: "2024-01", Key: "old-secret-with-at-least-32-bytes!!"}},
+	}
+	encoder := jwtusecase.NewKeyringEncoder(hasher, ring)
+	verifier := jwtusecase.NewVerifier(hasher, jwtusecase.WithKeySource(ring))
+
+	oldToken, err := encoder.Encode(map[string]any{"sub": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate: add the new key and make it current; the old one stays accepted
+	ring.Keys = append([]jwt.RingKey{{ID: "2024-06", Key: "new-secret-with-at-least-32-bytes!!"}}, ring.Keys...)
+	ring.Current = "2024-06"
+	newToken, err := encoder.Encode(map[string]any{"sub": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := verifier.Verify(token); err != nil {
+			t.Errorf("Expected %s token to verify, got %v", name, err)
+		}
+	}
+	parsed, _ := verifier.Verify(newToken)
+	if parsed.Header["kid"] != "2024-06" {
+		t.Errorf("Expected new token to be signed with the current key, got kid %v", parsed.Header["kid"])
+	}
+
+	ring.Current = ""
+	if _, err := encoder.Encode(map[string]any{}); !errors.Is(err, jwt.ErrNoActiveKey) {
+		t.Errorf("Expected ErrNoActiveKey without a current key, got %v", err)
+	}
+}
+
+func TestKeyEncoder_ParsedKeys(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256, err := hash.NewHasher(hash.RS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := rs256.(hash.KeyHasher)
+
+	token, err := jwtusecase.NewKeyEncoder(hasher, privateKey).Encode(map[string]any{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	verified, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithVerificationKey(&privateKey.PublicKey)).Verify(token)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if verified.Claims["sub"] != "alice" {
+		t.Errorf("Unexpected claims: %v", verified.Claims)
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithVerificationKey(&other.PublicKey)).Verify(token); !errors.Is(err, hash.ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for another key, got %v", err)
+	}
+
+	// A hasher without parsed key support cannot use WithVerificationKey
+	mock := &hash.MockHasher{NameFunc: func() string { return "RS256" }}
+	if _, err := jwtusecase.NewVerifier(mock, jwtusecase.WithVerificationKey(&privateKey.PublicKey)).Verify(token); !errors.Is(err, hash.ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for a hasher without parsed keys, got %v", err)
+	}
+}
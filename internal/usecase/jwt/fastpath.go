@@ -0,0 +1,206 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+)
+
+const (
+	// maxCachedHeaders bounds how many distinct accepted header segments a
+	// FastVerifier remembers
+	maxCachedHeaders = 16
+	// maxPooledBuffer is the largest buffer returned to the pool
+	maxPooledBuffer = 16 << 10
+)
+
+// Errors returned by FastVerifier; they are preallocated so rejecting a
+// token does not allocate
+var (
+	errFastEmpty           = errors.New("empty token provided")
+	errFastParts           = fmt.Errorf("%w: expected 3 parts", jwt.ErrMalformedToken)
+	errFastHeaderBase64    = fmt.Errorf("%w: part 1 is not valid base64", jwt.ErrMalformedToken)
+	errFastPayloadBase64   = fmt.Errorf("%w: part 2 is not valid base64", jwt.ErrMalformedToken)
+	errFastSignatureBase64 = fmt.Errorf("%w: part 3 is not valid base64", jwt.ErrMalformedToken)
+	errFastHeaderJSON      = fmt.Errorf("%w: header is not valid JSON", jwt.ErrMalformedToken)
+)
+
+// bufferPool holds scratch buffers for the token and its decoded segments
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// FastVerifier is a signature-only path for servers checking many tokens
+// against one parsed key. Each segment is decoded once into pooled buffers,
+// nothing is formatted and claims are not validated, so unlike
+// Decoder.Verify it accepts expired tokens. Accepted header
+// segments are remembered, so with an HMAC key a token whose header was
+// seen before is verified without allocating.
+type FastVerifier struct {
+	hasher hash.KeyHasher
+	key    crypto.PublicKey
+	crit   *jwt.Extensions
+
+	mu      sync.RWMutex
+	headers map[string]struct{}
+}
+
+// FastVerifierOption configures optional FastVerifier behaviour
+type FastVerifierOption func(*FastVerifier)
+
+// WithFastExtensions sets the registry of header extensions a crit header
+// may list, like WithExtensions does for the Decoder. Without it
+// jwt.DefaultExtensions is used.
+func WithFastExtensions(extensions *jwt.Extensions) FastVerifierOption {
+	return func(v *FastVerifier) {
+		v.crit = extensions
+	}
+}
+
+// NewFastVerifier creates a verifier for tokens signed with the hasher's
+// algorithm, using a key from the hasher's ParseVerificationKey
+func NewFastVerifier(hasher hash.KeyHasher, key crypto.PublicKey, opts ...FastVerifierOption) *FastVerifier {
+	v := &FastVerifier{
+		hasher:  hasher,
+		key:     key,
+		crit:    jwt.DefaultExtensions,
+		headers: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// VerifySignature checks the token's structure, algorithm and signature. It
+// does not check exp, nbf, iss or aud; use AppendPayload and validate the
+// claims, or Decoder.Verify, when they matter.
+func (v *FastVerifier) VerifySignature(token string) error {
+	_, err := v.verify(nil, token, false)
+	return err
+}
+
+// AppendPayload verifies the token like VerifySignature and appends its decoded
+// payload to dst, so callers can unmarshal and check the claims themselves
+func (v *FastVerifier) AppendPayload(dst []byte, token string) ([]byte, error) {
+	return v.verify(dst, token, true)
+}
+
+// verify makes a single pass over the token's segments. The payload is
+// decoded into dst when appendPayload is set and into scratch space
+// otherwise, so malformed payloads are rejected either way.
+func (v *FastVerifier) verify(dst []byte, token string, appendPayload bool) ([]byte, error) {
+	if token == "" {
+		return dst, errFastEmpty
+	}
+	first := strings.IndexByte(token, '.')
+	if first < 0 {
+		return dst, errFastParts
+	}
+	second := strings.IndexByte(token[first+1:], '.')
+	if second < 0 {
+		return dst, errFastParts
+	}
+	second += first + 1
+	if strings.IndexByte(token[second+1:], '.') >= 0 {
+		return dst, errFastParts
+	}
+
+	if err := v.checkHeader(token[:first]); err != nil {
+		return dst, err
+	}
+
+	// Copy the token once so its segments can be used as byte slices; the
+	// decoded signature (and payload, if not appended to dst) follow it
+	payloadLen := base64.RawURLEncoding.DecodedLen(second - first - 1)
+	signatureLen := base64.RawURLEncoding.DecodedLen(len(token) - second - 1)
+	size := len(token) + signatureLen
+	if !appendPayload {
+		size += payloadLen
+	}
+	bp := bufferPool.Get().(*[]byte)
+	buf := *bp
+	if cap(buf) < size {
+		buf = make([]byte, 0, size)
+	}
+	buf = append(buf[:0], token...)
+	defer func() {
+		if cap(buf) <= maxPooledBuffer {
+			*bp = buf[:0]
+			bufferPool.Put(bp)
+		}
+	}()
+
+	signature := buf[len(token) : len(token)+signatureLen]
+	n, err := base64.RawURLEncoding.Decode(signature, buf[second+1:len(token)])
+	if err != nil {
+		return dst, errFastSignatureBase64
+	}
+	if err := v.hasher.VerifyWithKey(buf[:second], signature[:n], v.key); err != nil {
+		return dst, err
+	}
+
+	encodedPayload := buf[first+1 : second]
+	if !appendPayload {
+		scratch := buf[len(token)+signatureLen : size]
+		if _, err := base64.RawURLEncoding.Decode(scratch, encodedPayload); err != nil {
+			return dst, errFastPayloadBase64
+		}
+		return dst, nil
+	}
+
+	start := len(dst)
+	if cap(dst)-start < payloadLen {
+		grown := make([]byte, start, start+payloadLen)
+		copy(grown, dst)
+		dst = grown
+	}
+	n, err = base64.RawURLEncoding.Decode(dst[start:start+payloadLen], encodedPayload)
+	if err != nil {
+		return dst[:start], errFastPayloadBase64
+	}
+	return dst[:start+n], nil
+}
+
+// checkHeader accepts header segments seen before without decoding them;
+// new ones are parsed, their algorithm checked against the hasher and their
+// crit header against the verifier's extensions
+func (v *FastVerifier) checkHeader(segment string) error {
+	v.mu.RLock()
+	_, ok := v.headers[segment]
+	v.mu.RUnlock()
+	if ok {
+		return nil
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errFastHeaderBase64
+	}
+	headerMap, err := jwt.ParseObject(header)
+	if err != nil {
+		return errFastHeaderJSON
+	}
+	if alg, ok := headerMap["alg"].(string); !ok || alg != v.hasher.Name() {
+		return fmt.Errorf("%w: %v", hash.ErrUnsupportedAlgorithm, headerMap["alg"])
+	}
+	if err := v.crit.Check(headerMap); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	if len(v.headers) < maxCachedHeaders {
+		v.headers[strings.Clone(segment)] = struct{}{}
+	}
+	v.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,223 @@
+package jwt_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+var fastSecret = []byte(strings.Repeat("fast path secret ", 4))
+
+// fastHS256 returns an HS256 KeyHasher, its parsed key and a signed token
+func fastHS256(t testing.TB) (hash.KeyHasher, any, string) {
+	t.Helper()
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyed := hasher.(hash.KeyHasher)
+	key, err := keyed.ParseVerificationKey(fastSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwtusecase.NewEncoder(hasher, fastSecret).Encode(map[string]any{"sub": "user-1", "scope": "read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyed, key, token
+}
+
+func TestFastVerifier(t *testing.T) {
+	hasher, key, token := fastHS256(t)
+	parts := strings.Split(token, ".")
+	other, err := jwtusecase.NewEncoder(hasher, []byte(strings.Repeat("another secret ", 5))).Encode(map[string]any{"sub": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs384Header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS384","typ":"JWT"}`))
+	critical, err := jwtusecase.NewEncoder(hasher, fastSecret, jwtusecase.WithHeader("crit", []string{"tenant"}), jwtusecase.WithHeader("tenant", "t1")).Encode(map[string]any{"sub": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+		errText string
+	}{
+		{name: "Valid", token: token},
+		{name: "Empty", token: "", errText: "empty token"},
+		{name: "Two parts", token: parts[0] + "." + parts[1], errText: "expected 3 parts"},
+		{name: "Four parts", token: token + ".x", errText: "expected 3 parts"},
+		{name: "Wrong key", token: other, wantErr: hash.ErrSignatureInvalid},
+		{name: "Tampered payload", token: parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2], wantErr: hash.ErrSignatureInvalid},
+		{name: "Other algorithm", token: hs384Header + "." + parts[1] + "." + parts[2], wantErr: hash.ErrUnsupportedAlgorithm},
+		{name: "Header not base64", token: "!!." + parts[1] + "." + parts[2], errText: "part 1 is not valid base64"},
+		{name: "Header not JSON", token: "bm90IGpzb24." + parts[1] + "." + parts[2], errText: "header is not valid JSON"},
+		{name: "Signature not base64", token: parts[0] + "." + parts[1] + ".!!", errText: "part 3 is not valid base64"},
+		{name: "Unknown critical extension", token: critical, wantErr: jwt.ErrCriticalHeader},
+	}
+
+	verifier := jwtusecase.NewFastVerifier(hasher, key)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifier.VerifySignature(tt.token)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("Expected error containing %q, got %v", tt.errText, err)
+				}
+			case err != nil:
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFastVerifier_WithFastExtensions(t *testing.T) {
+	hasher, key, _ := fastHS256(t)
+	token, err := jwtusecase.NewEncoder(hasher, fastSecret, jwtusecase.WithHeader("crit", []string{"tenant"}), jwtusecase.WithHeader("tenant", "t1")).Encode(map[string]any{"sub": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	extensions := jwt.NewExtensions("tenant")
+
+	if err := jwtusecase.NewFastVerifier(hasher, key, jwtusecase.WithFastExtensions(extensions)).VerifySignature(token); err != nil {
+		t.Errorf("Expected the registered extension to be accepted, got %v", err)
+	}
+	// The Decoder given the same registry agrees
+	if _, err := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(fastSecret), jwtusecase.WithExtensions(extensions)).Verify(token); err != nil {
+		t.Errorf("Expected the Decoder to accept the token too, got %v", err)
+	}
+	if err := jwtusecase.NewFastVerifier(hasher, key, jwtusecase.WithFastExtensions(jwt.NewExtensions())).VerifySignature(token); !errors.Is(err, jwt.ErrCriticalHeader) {
+		t.Errorf("Expected ErrCriticalHeader with an empty registry, got %v", err)
+	}
+}
+
+func TestFastVerifier_AppendPayload(t *testing.T) {
+	hasher, key, token := fastHS256(t)
+	verifier := jwtusecase.NewFastVerifier(hasher, key)
+
+	payload, err := verifier.AppendPayload([]byte("prefix:"), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(payload), "prefix:") {
+		t.Fatalf("dst was not preserved: %q", payload)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload[len("prefix:"):], &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims["sub"] != "user-1" || claims["scope"] != "read" {
+		t.Errorf("Unexpected claims: %v", claims)
+	}
+
+	if _, err := verifier.AppendPayload(nil, token+"x"); err == nil {
+		t.Error("Expected error for a tampered token")
+	}
+}
+
+func TestFastVerifier_RS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256, err := hash.NewHasher(hash.RS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := rs256.(hash.KeyHasher)
+	token, err := jwtusecase.NewKeyEncoder(hasher, privateKey).Encode(map[string]any{"sub": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := jwtusecase.NewFastVerifier(hasher, &privateKey.PublicKey).VerifySignature(token); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestFastVerifier_Allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool does not retain buffers under the race detector")
+	}
+	hasher, key, token := fastHS256(t)
+	verifier := jwtusecase.NewFastVerifier(hasher, key)
+	if err := verifier.VerifySignature(token); err != nil {
+		t.Fatal(err)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		if err := verifier.VerifySignature(token); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs != 0 {
+		t.Errorf("VerifySignature allocated %v times per call, want 0", allocs)
+	}
+
+	dst := make([]byte, 0, 256)
+	if allocs := testing.AllocsPerRun(100, func() {
+		if _, err := verifier.AppendPayload(dst[:0], token); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs != 0 {
+		t.Errorf("AppendPayload allocated %v times per call, want 0", allocs)
+	}
+
+	// Rejections use preallocated errors
+	tampered := token[:len(token)-2] + "AA"
+	if allocs := testing.AllocsPerRun(100, func() {
+		if err := verifier.VerifySignature(tampered); err == nil {
+			t.Fatal("Expected error for a tampered token")
+		}
+	}); allocs != 0 {
+		t.Errorf("Rejecting a token allocated %v times per call, want 0", allocs)
+	}
+}
+
+func BenchmarkFastVerifier_HS256(b *testing.B) {
+	hasher, key, token := fastHS256(b)
+	verifier := jwtusecase.NewFastVerifier(hasher, key)
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := verifier.VerifySignature(token); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoder_VerifyHS256(b *testing.B) {
+	hasher, _, token := fastHS256(b)
+	verifier := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(fastSecret))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := verifier.Verify(token); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoder_DecodeHS256(b *testing.B) {
+	hasher, _, token := fastHS256(b)
+	decoder := jwtusecase.NewDecoder(hasher, jwtusecase.WithKey(fastSecret))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := decoder.Decode(token, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+// Defaults for PairIssuer
+const (
+	// DefaultAccessTTL is the lifetime of access tokens
+	DefaultAccessTTL = 15 * time.Minute
+	// DefaultRefreshTTL is the lifetime of refresh tokens
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+	// familyClaim links refresh tokens rotated from one another
+	familyClaim = "fid"
+)
+
+// registeredPairClaims are set by the issuer and never copied from a refresh
+// token into the renewed pair
+var registeredPairClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", familyClaim}
+
+// PairConfig configures a PairIssuer
+type PairConfig struct {
+	// Encoder signs both access and refresh tokens
+	Encoder jwt.Encoder
+	// Verifier checks refresh tokens. Its policy must accept the refresh
+	// audience; the issuer checks the audience itself.
+	Verifier jwt.Verifier
+	// Store tracks refresh tokens to detect reuse
+	Store jwt.RefreshStore
+	// Issuer is the iss claim of issued tokens
+	Issuer string
+	// AccessAudience and RefreshAudience must differ so that neither token
+	// is accepted in place of the other
+	AccessAudience  string
+	RefreshAudience string
+	// AccessTTL and RefreshTTL default to DefaultAccessTTL and DefaultRefreshTTL
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// PairIssuer issues access/refresh token pairs and rotates refresh tokens
+type PairIssuer struct {
+	cfg PairConfig
+}
+
+// NewPairIssuer checks the configuration and creates an issuer
+func NewPairIssuer(cfg PairConfig) (*PairIssuer, error) {
+	if cfg.Encoder == nil || cfg.Verifier == nil || cfg.Store == nil {
+		return nil, fmt.Errorf("encoder, verifier and store are required")
+	}
+	if cfg.AccessAudience == "" || cfg.RefreshAudience == "" || cfg.AccessAudience == cfg.RefreshAudience {
+		return nil, fmt.Errorf("access and refresh audiences must be set and distinct")
+	}
+	if cfg.AccessTTL <= 0 {
+		cfg.AccessTTL = DefaultAccessTTL
+	}
+	if cfg.RefreshTTL <= 0 {
+		cfg.RefreshTTL = DefaultRefreshTTL
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &PairIssuer{cfg: cfg}, nil
+}
+
+// Issue starts a new refresh token family for the subject. The extra claims
+// are added to the access token and carried over on every refresh.
+func (p *PairIssuer) Issue(subject string, claims map[string]any) (*jwt.TokenPair, error) {
+	return p.issue(subject, newTokenID(), claims)
+}
+
+// Refresh exchanges a refresh token for a new pair. Presenting a refresh
+// token a second time revokes its whole family, including the newest token.
+func (p *PairIssuer) Refresh(refreshToken string) (*jwt.TokenPair, error) {
+	token, family, err := p.verifyRefresh(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	jti, _ := token.Claims["jti"].(string)
+	if err := p.cfg.Store.Use(family, jti); err != nil {
+		if errors.Is(err, jwt.ErrRefreshReused) {
+			if revokeErr := p.cfg.Store.RevokeFamily(family); revokeErr != nil {
+				return nil, fmt.Errorf("%w (revoking token family failed: %v)", err, revokeErr)
+			}
+		}
+		return nil, err
+	}
+
+	subject, _ := token.Claims["sub"].(string)
+	claims := make(map[string]any, len(token.Claims))
+	for name, value := range token.Claims {
+		claims[name] = value
+	}
+	for _, name := range registeredPairClaims {
+		delete(claims, name)
+	}
+	return p.issue(subject, family, claims)
+}
+
+// Revoke invalidates the refresh token's family, for example on logout
+func (p *PairIssuer) Revoke(refreshToken string) error {
+	_, family, err := p.verifyRefresh(refreshToken)
+	if err != nil {
+		return err
+	}
+	return p.cfg.Store.RevokeFamily(family)
+}
+
+// verifyRefresh checks the signature and audience of a refresh token and
+// returns it with its family ID
+func (p *PairIssuer) verifyRefresh(refreshToken string) (*jwt.Token, string, error) {
+	token, err := p.cfg.Verifier.Verify(refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+	registered, err := jwt.UnmarshalClaims[jwt.RegisteredClaims](token)
+	if err != nil {
+		return nil, "", err
+	}
+	if !registered.Audience.Contains(p.cfg.RefreshAudience) {
+		return nil, "", fmt.Errorf("not a refresh token: invalid audience: %v", token.Claims["aud"])
+	}
+	family, _ := token.Claims[familyClaim].(string)
+	if family == "" {
+		return nil, "", fmt.Errorf("not a refresh token: missing %s claim", familyClaim)
+	}
+	return token, family, nil
+}
+
+// issue signs a pair in the given family and records the refresh token
+func (p *PairIssuer) issue(subject, family string, claims map[string]any) (*jwt.TokenPair, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+	now := p.cfg.Now()
+
+	access := p.claims(subject, p.cfg.AccessAudience, now, p.cfg.AccessTTL, claims)
+	accessToken, err := p.cfg.Encoder.Encode(access)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshExpires := now.Add(p.cfg.RefreshTTL)
+	refresh := p.claims(subject, p.cfg.RefreshAudience, now, p.cfg.RefreshTTL, claims)
+	refresh[familyClaim] = family
+	refreshToken, err := p.cfg.Encoder.Encode(refresh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign refresh token: %w", err)
+	}
+	if err := p.cfg.Store.Add(family, refresh["jti"].(string), refreshExpires); err != nil {
+		return nil, fmt.Errorf("failed to record refresh token: %w", err)
+	}
+
+	return &jwt.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(p.cfg.AccessTTL / time.Second),
+	}, nil
+}
+
+// claims builds the claim set of one token of a pair
+func (p *PairIssuer) claims(subject, audience string, now time.Time, ttl time.Duration, extra map[string]any) map[string]any {
+	claims := make(map[string]any, len(extra)+6)
+	for name, value := range extra {
+		claims[name] = value
+	}
+	if p.cfg.Issuer != "" {
+		claims["iss"] = p.cfg.Issuer
+	}
+	claims["sub"] = subject
+	claims["aud"] = audience
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	claims["jti"] = newTokenID()
+	return claims
+}
+
+// newTokenID returns a random identifier for jti and family claims
+func newTokenID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,110 @@
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+	"jwt/internal/interface/refresh"
+	jwtusecase "jwt/internal/usecase/jwt"
+)
+
+func newPairIssuer(t *testing.T) (*jwtusecase.PairIssuer, jwt.Verifier) {
+	t.Helper()
+	hasher, err := hash.NewHasher(hash.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("pair-test-secret-with-32-bytes!!!!")
+	issuer, err := jwtusecase.NewPairIssuer(jwtusecase.PairConfig{
+		Encoder:         jwtusecase.NewEncoder(hasher, secret),
+		Verifier:        jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(secret)),
+		Store:           refresh.NewMemoryStore(),
+		Issuer:          "https://auth.example.com",
+		AccessAudience:  "api",
+		RefreshAudience: "refresh",
+		AccessTTL:       5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessVerifier := jwtusecase.NewVerifier(hasher, jwtusecase.WithKey(secret), jwtusecase.WithPolicy(jwt.Policy{Audience: "api"}))
+	return issuer, accessVerifier
+}
+
+func TestPairIssuer_IssueAndRefresh(t *testing.T) {
+	issuer, accessVerifier := newPairIssuer(t)
+
+	pair, err := issuer.Issue("alice", map[string]any{"scope": "read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pair.TokenType != "Bearer" || pair.ExpiresIn != 300 {
+		t.Errorf("Unexpected token response: %+v", pair)
+	}
+	if _, err := accessVerifier.Verify(pair.RefreshToken); err == nil {
+		t.Error("Expected refresh token to be rejected as an access token")
+	}
+	if _, err := issuer.Refresh(pair.AccessToken); err == nil {
+		t.Error("Expected access token to be rejected as a refresh token")
+	}
+
+	renewed, err := issuer.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	access, err := accessVerifier.Verify(renewed.AccessToken)
+	if err != nil {
+		t.Fatalf("Renewed access token did not verify: %v", err)
+	}
+	if access.Claims["sub"] != "alice" || access.Claims["scope"] != "read" || access.Claims["iss"] != "https://auth.example.com" {
+		t.Errorf("Expected claims to carry over, got %v", access.Claims)
+	}
+	if _, ok := access.Claims["fid"]; ok {
+		t.Error("Expected the family claim to stay out of access tokens")
+	}
+}
+
+func TestPairIssuer_ReuseRevokesFamily(t *testing.T) {
+	issuer, _ := newPairIssuer(t)
+
+	first, err := issuer.Issue("alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := issuer.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := issuer.Refresh(first.RefreshToken); !errors.Is(err, jwt.ErrRefreshReused) {
+		t.Fatalf("Expected ErrRefreshReused, got %v", err)
+	}
+	if _, err := issuer.Refresh(second.RefreshToken); !errors.Is(err, jwt.ErrRevoked) {
+		t.Errorf("Expected the newest token of the family to be revoked, got %v", err)
+	}
+
+	other, _ := issuer.Issue("bob", nil)
+	if err := issuer.Revoke(other.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issuer.Refresh(other.RefreshToken); !errors.Is(err, jwt.ErrRevoked) {
+		t.Errorf("Expected revoked family to be refused, got %v", err)
+	}
+}
+
+func TestNewPairIssuer_Audiences(t *testing.T) {
+	hasher, _ := hash.NewHasher(hash.HS256)
+	_, err := jwtusecase.NewPairIssuer(jwtusecase.PairConfig{
+		Encoder:         jwtusecase.NewEncoder(hasher, []byte("k")),
+		Verifier:        jwtusecase.NewVerifier(hasher),
+		Store:           refresh.NewMemoryStore(),
+		AccessAudience:  "api",
+		RefreshAudience: "api",
+	})
+	if err == nil {
+		t.Error("Expected error when access and refresh audiences are equal")
+	}
+}
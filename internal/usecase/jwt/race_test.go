@@ -0,0 +1,7 @@
+//go:build race
+
+package jwt_test
+
+// raceEnabled reports whether tests run under the race detector, which makes
+// sync.Pool drop items and so breaks allocation counts
+const raceEnabled = true
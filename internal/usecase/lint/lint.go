@@ -0,0 +1,139 @@
+// Package lint audits decoded tokens against a configurable rule set
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"jwt/internal/domain/jwt"
+)
+
+// Severity ranks findings
+type Severity int
+
+// Supported severities, lowest first
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+// String returns the lower-case severity name
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// ParseSeverity parses "info", "warning" or "error"
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "info":
+		return Info, nil
+	case "warning", "warn":
+		return Warning, nil
+	case "error":
+		return Error, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q: expected info, warning or error", s)
+}
+
+// Finding is a problem reported by a rule
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+// Rule checks one property of a token. Check returns a message per problem.
+type Rule struct {
+	Name        string
+	Severity    Severity
+	Description string
+	Check       func(token *jwt.Token, cfg Config) []string
+}
+
+// Config tunes the rules
+type Config struct {
+	// SecretKey, when known, lets HMAC key strength be checked
+	SecretKey []byte
+	// MaxLifetime is the longest acceptable exp - iat; defaults to 24h
+	MaxLifetime time.Duration
+	// Leeway is the clock skew tolerated for iat; defaults to 1m
+	Leeway time.Duration
+	// PIIClaims are claim names treated as personal data; defaults to
+	// DefaultPIIClaims
+	PIIClaims []string
+	// Disabled lists rule names to skip
+	Disabled []string
+	// Severities overrides the severity of rules by name
+	Severities map[string]Severity
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// Linter runs a rule set over tokens
+type Linter struct {
+	rules []Rule
+	cfg   Config
+}
+
+// New creates a linter with the given rules, or DefaultRules when none are
+// given. Append to DefaultRules to add custom rules.
+func New(cfg Config, rules ...Rule) *Linter {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	if cfg.MaxLifetime <= 0 {
+		cfg.MaxLifetime = 24 * time.Hour
+	}
+	if cfg.Leeway <= 0 {
+		cfg.Leeway = time.Minute
+	}
+	if cfg.PIIClaims == nil {
+		cfg.PIIClaims = DefaultPIIClaims
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Linter{rules: rules, cfg: cfg}
+}
+
+// Rules returns the linter's rules
+func (l *Linter) Rules() []Rule {
+	return l.rules
+}
+
+// Lint runs every enabled rule and returns the findings, most severe first
+func (l *Linter) Lint(token *jwt.Token) []Finding {
+	disabled := make(map[string]bool, len(l.cfg.Disabled))
+	for _, name := range l.cfg.Disabled {
+		disabled[name] = true
+	}
+
+	var findings []Finding
+	for _, rule := range l.rules {
+		if disabled[rule.Name] {
+			continue
+		}
+		severity := rule.Severity
+		if s, ok := l.cfg.Severities[rule.Name]; ok {
+			severity = s
+		}
+		for _, msg := range rule.Check(token, l.cfg) {
+			findings = append(findings, Finding{Rule: rule.Name, Severity: severity, Message: msg})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+	return findings
+}
@@ -0,0 +1,106 @@
+package lint_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"jwt/internal/domain/jwt"
+	"jwt/internal/usecase/lint"
+)
+
+func TestLinter_DefaultRules(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	good := func() *jwt.Token {
+		return &jwt.Token{
+			Header: map[string]any{"alg": "HS256", "typ": "JWT"},
+			Claims: map[string]any{"sub": "user", "iat": float64(now.Unix()), "exp": float64(now.Unix() + 3600)},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*jwt.Token)
+		key    string
+		want   string
+	}{
+		{"Clean token", func(*jwt.Token) {}, "0123456789abcdef0123456789abcdef", ""},
+		{"alg none", func(tk *jwt.Token) { tk.Header["alg"] = "none" }, "", "alg-none"},
+		{"Short HMAC key", func(*jwt.Token) {}, "secret", "weak-hmac-key"},
+		{"Missing exp", func(tk *jwt.Token) { delete(tk.Claims, "exp") }, "", "missing-exp"},
+		{"Long lifetime", func(tk *jwt.Token) { tk.Claims["exp"] = float64(now.Unix() + 48*3600) }, "", "long-lifetime"},
+		{"Future iat", func(tk *jwt.Token) { tk.Claims["iat"] = float64(now.Unix() + 600) }, "", "future-iat"},
+		{"Future iat as json.Number", func(tk *jwt.Token) { tk.Claims["iat"] = json.Number(strconv.FormatInt(now.Unix()+600, 10)) }, "", "future-iat"},
+		{"PII claim", func(tk *jwt.Token) { tk.Claims["email"] = "a@example.com" }, "", "pii-claims"},
+		{"Remote jku", func(tk *jwt.Token) { tk.Header["jku"] = "https://evil.example.com/jwks.json" }, "", "remote-key-url"},
+		{"kid traversal", func(tk *jwt.Token) { tk.Header["kid"] = "../../dev/null" }, "", "kid-path-traversal"},
+		{"Missing typ", func(tk *jwt.Token) { delete(tk.Header, "typ") }, "", "missing-typ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := good()
+			tt.modify(token)
+			findings := lint.New(lint.Config{SecretKey: []byte(tt.key), Now: func() time.Time { return now }}).Lint(token)
+
+			if tt.want == "" {
+				if len(findings) != 0 {
+					t.Errorf("Expected no findings, got %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 || findings[0].Rule != tt.want {
+				t.Errorf("Expected a single %s finding, got %+v", tt.want, findings)
+			}
+		})
+	}
+}
+
+func TestLinter_Config(t *testing.T) {
+	token := &jwt.Token{
+		Header: map[string]any{"alg": "none"},
+		Claims: map[string]any{"email": "a@example.com"},
+	}
+	custom := lint.Rule{
+		Name:     "require-sub",
+		Severity: lint.Error,
+		Check: func(token *jwt.Token, _ lint.Config) []string {
+			if _, ok := token.Claims["sub"]; !ok {
+				return []string{"no sub claim"}
+			}
+			return nil
+		},
+	}
+	linter := lint.New(lint.Config{
+		Disabled:   []string{"alg-none", "missing-exp"},
+		Severities: map[string]lint.Severity{"pii-claims": lint.Error, "missing-typ": lint.Warning},
+	}, append(lint.DefaultRules(), custom)...)
+
+	findings := linter.Lint(token)
+	got := map[string]lint.Severity{}
+	for _, f := range findings {
+		got[f.Rule] = f.Severity
+	}
+	want := map[string]lint.Severity{"pii-claims": lint.Error, "require-sub": lint.Error, "missing-typ": lint.Warning}
+	if len(got) != len(want) {
+		t.Fatalf("Expected findings %v, got %+v", want, findings)
+	}
+	for rule, severity := range want {
+		if got[rule] != severity {
+			t.Errorf("Expected %s at %v, got %v", rule, severity, got[rule])
+		}
+	}
+	if findings[len(findings)-1].Severity != lint.Warning {
+		t.Error("Expected findings to be ordered by severity")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := lint.ParseSeverity("WARNING"); err != nil || s != lint.Warning {
+		t.Errorf("Expected warning, got %v, %v", s, err)
+	}
+	if _, err := lint.ParseSeverity("fatal"); err == nil {
+		t.Error("Expected error for unknown severity")
+	}
+}
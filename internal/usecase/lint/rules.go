@@ -0,0 +1,139 @@
+package lint
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"jwt/internal/domain/hash"
+	"jwt/internal/domain/jwt"
+)
+
+// DefaultPIIClaims are claim names that usually carry personal data
+var DefaultPIIClaims = []string{
+	"email", "phone_number", "address", "birthdate", "name", "given_name",
+	"family_name", "ssn", "national_id", "passport", "ip", "ip_address",
+}
+
+// DefaultRules returns the built-in rules
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "alg-none", Severity: Error, Description: "alg is none, so the token is unsigned", Check: checkAlgNone},
+		{Name: "weak-hmac-key", Severity: Error, Description: "HMAC key shorter than the hash output (RFC 7518 section 3.2)", Check: checkWeakHMACKey},
+		{Name: "missing-exp", Severity: Warning, Description: "token never expires", Check: checkMissingExp},
+		{Name: "long-lifetime", Severity: Warning, Description: "exp - iat exceeds the maximum lifetime", Check: checkLifetime},
+		{Name: "future-iat", Severity: Warning, Description: "iat lies in the future", Check: checkFutureIat},
+		{Name: "pii-claims", Severity: Warning, Description: "payload carries personal data readable by anyone", Check: checkPII},
+		{Name: "remote-key-url", Severity: Warning, Description: "jku or x5u header points to a remote URL", Check: checkRemoteKeyURL},
+		{Name: "kid-path-traversal", Severity: Error, Description: "kid looks like a file path or contains traversal sequences", Check: checkKidTraversal},
+		{Name: "missing-typ", Severity: Info, Description: "typ header is missing", Check: checkMissingTyp},
+	}
+}
+
+// checkAlgNone flags unsigned tokens
+func checkAlgNone(token *jwt.Token, _ Config) []string {
+	if alg, _ := token.Header["alg"].(string); strings.EqualFold(alg, "none") {
+		return []string{"alg is \"none\": the token carries no signature"}
+	}
+	return nil
+}
+
+// checkWeakHMACKey flags HMAC secrets shorter than the algorithm's minimum
+func checkWeakHMACKey(token *jwt.Token, cfg Config) []string {
+	alg, _ := token.Header["alg"].(string)
+	size := hash.MinKeySize(hash.Algorithm(alg))
+	if size == 0 || len(cfg.SecretKey) == 0 {
+		return nil
+	}
+	if len(cfg.SecretKey) < size {
+		return []string{fmt.Sprintf("%s key is %d bytes; at least %d are required", alg, len(cfg.SecretKey), size)}
+	}
+	return nil
+}
+
+// checkMissingExp flags tokens without an exp claim
+func checkMissingExp(token *jwt.Token, _ Config) []string {
+	if _, ok := token.Claims["exp"]; !ok {
+		return []string{"no exp claim: the token never expires"}
+	}
+	return nil
+}
+
+// checkLifetime flags tokens living longer than cfg.MaxLifetime
+func checkLifetime(token *jwt.Token, cfg Config) []string {
+	exp, okExp := claimTime(token, "exp")
+	iat, okIat := claimTime(token, "iat")
+	if !okExp || !okIat {
+		return nil
+	}
+	if lifetime := exp.Sub(iat); lifetime > cfg.MaxLifetime {
+		return []string{fmt.Sprintf("lifetime is %v, longer than %v", lifetime, cfg.MaxLifetime)}
+	}
+	return nil
+}
+
+// checkFutureIat flags iat claims later than now plus the leeway
+func checkFutureIat(token *jwt.Token, cfg Config) []string {
+	iat, ok := claimTime(token, "iat")
+	if ok && iat.After(cfg.Now().Add(cfg.Leeway)) {
+		return []string{fmt.Sprintf("iat is %s, in the future", iat.UTC().Format(time.RFC3339))}
+	}
+	return nil
+}
+
+// checkPII flags claims named in cfg.PIIClaims
+func checkPII(token *jwt.Token, cfg Config) []string {
+	var messages []string
+	for _, name := range cfg.PIIClaims {
+		if _, ok := token.Claims[name]; ok {
+			messages = append(messages, fmt.Sprintf("claim %q may contain personal data; token payloads are only encoded, not encrypted", name))
+		}
+	}
+	return messages
+}
+
+// checkRemoteKeyURL flags jku and x5u headers
+func checkRemoteKeyURL(token *jwt.Token, _ Config) []string {
+	var messages []string
+	for _, name := range []string{"jku", "x5u"} {
+		raw, ok := token.Header[name].(string)
+		if !ok {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			messages = append(messages, fmt.Sprintf("%s header %q is not an absolute URL", name, raw))
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s header points to %s; keys must only be fetched from trusted URLs", name, raw))
+	}
+	return messages
+}
+
+// checkKidTraversal flags kid values with path separators or ".."
+func checkKidTraversal(token *jwt.Token, _ Config) []string {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil
+	}
+	if strings.Contains(kid, "..") || strings.ContainsAny(kid, "/\\\x00") {
+		return []string{fmt.Sprintf("kid %q contains path characters; never use it to build file paths or queries", kid)}
+	}
+	return nil
+}
+
+// checkMissingTyp flags tokens without a typ header
+func checkMissingTyp(token *jwt.Token, _ Config) []string {
+	if _, ok := token.Header["typ"]; !ok {
+		return []string{"no typ header: tokens of different kinds cannot be told apart"}
+	}
+	return nil
+}
+
+// claimTime reads a NumericDate claim like claim validation does, treating
+// malformed values as absent
+func claimTime(token *jwt.Token, name string) (time.Time, bool) {
+	t, ok, err := jwt.ClaimTime(token.Claims, name)
+	return t, ok && err == nil
+}